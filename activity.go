@@ -0,0 +1,63 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// activityGem is a gem entry from the activity feeds, which carry the
+// publish time of the gem's latest version alongside the usual gem info.
+type activityGem struct {
+	GemInfo
+	VersionCreatedAt time.Time `json:"version_created_at"`
+}
+
+// GemsUpdatedSince returns gems whose latest version was published after since,
+// newest first, using the /activity/just_updated.json feed.
+//
+// The feed only covers the most recent releases (50 on rubygems.org), so a
+// mirror must poll more often than that window rolls over to avoid gaps.
+// Returns ErrNotSupported if the server has no activity feed.
+func (c *Client) GemsUpdatedSince(since time.Time) ([]GemInfo, error) {
+	return c.GemsUpdatedSinceContext(context.Background(), since)
+}
+
+// GemsUpdatedSinceContext is like GemsUpdatedSince but aborts when ctx is canceled.
+func (c *Client) GemsUpdatedSinceContext(ctx context.Context, since time.Time) ([]GemInfo, error) {
+	url := fmt.Sprintf("%s/activity/just_updated.json", c.baseURL)
+
+	req, err := c.newRequest(ctx, "GET", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch updated gems: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("activity feed: %w", ErrNotSupported)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("RubyGems API returned status %d for activity feed", resp.StatusCode)
+	}
+
+	var feed []activityGem
+	if err := c.decodeJSON(resp, &feed); err != nil {
+		return nil, fmt.Errorf("failed to decode updated gems: %w", err)
+	}
+
+	var updated []GemInfo
+	for _, gem := range feed {
+		if !gem.VersionCreatedAt.After(since) {
+			continue
+		}
+		updated = append(updated, gem.GemInfo)
+	}
+
+	return updated, nil
+}
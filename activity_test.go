@@ -0,0 +1,61 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGemsUpdatedSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/activity/just_updated.json" {
+			t.Errorf("Expected path '/activity/just_updated.json', got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"name":"fresh","version":"2.0.0","version_created_at":"2026-03-02T10:00:00.000Z"},
+			{"name":"recent","version":"1.1.0","version_created_at":"2026-03-01T12:00:00.000Z"},
+			{"name":"stale","version":"0.9.0","version_created_at":"2026-02-01T00:00:00.000Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	gems, err := client.GemsUpdatedSince(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(gems) != 2 {
+		t.Fatalf("Expected 2 updated gems, got %d", len(gems))
+	}
+	if gems[0].Name != "fresh" || gems[0].Version != "2.0.0" {
+		t.Errorf("Expected fresh 2.0.0 first, got %s %s", gems[0].Name, gems[0].Version)
+	}
+	if gems[1].Name != "recent" {
+		t.Errorf("Expected recent second, got %s", gems[1].Name)
+	}
+}
+
+func TestGemsUpdatedSince_NotSupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	_, err := client.GemsUpdatedSince(time.Now())
+	if !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
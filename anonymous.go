@@ -0,0 +1,28 @@
+package rubygemsclient
+
+import "context"
+
+// WithAnonymous sends every request without credentials, even when
+// WithCredentials is set, e.g. to check that a gem is publicly available.
+// WithRequiredCredentialHosts is not enforced for an anonymous client.
+func WithAnonymous() ClientOption {
+	return func(c *Client) {
+		c.anonymous = true
+	}
+}
+
+// anonymousKey marks a context whose requests go without credentials.
+type anonymousKey struct{}
+
+// AnonymousContext returns a copy of ctx under which requests made by any
+// Client's Context methods carry no credentials, like WithAnonymous but for
+// individual calls.
+func AnonymousContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, anonymousKey{}, true)
+}
+
+// isAnonymous reports whether requests made with ctx skip credentials.
+func (c *Client) isAnonymous(ctx context.Context) bool {
+	anonymous, _ := ctx.Value(anonymousKey{}).(bool)
+	return c.anonymous || anonymous
+}
@@ -0,0 +1,62 @@
+package rubygemsclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWithAnonymous(t *testing.T) {
+	var mu sync.Mutex
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"name":"rack","version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	creds := &Credentials{Token: "secret"}
+	tests := []struct {
+		name     string
+		client   *Client
+		ctx      context.Context
+		wantAuth bool
+	}{
+		{"credentials", NewClientWithBaseURL(server.URL, WithCredentials(creds)), context.Background(), true},
+		{"anonymous client", NewClientWithBaseURL(server.URL, WithCredentials(creds), WithAnonymous()), context.Background(), false},
+		{"anonymous request", NewClientWithBaseURL(server.URL, WithCredentials(creds)), AnonymousContext(context.Background()), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth = nil
+			if _, err := tt.client.GetGemInfoContext(tt.ctx, "rack", "3.0.0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(auth) != 1 {
+				t.Fatalf("expected 1 request, got %d", len(auth))
+			}
+			if got := auth[0] != ""; got != tt.wantAuth {
+				t.Errorf("Authorization header %q, want present=%v", auth[0], tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestWithAnonymous_SkipsRequiredCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"rack","version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	required := WithRequiredCredentialHosts([]string{"127.0.0.1"})
+	if _, err := NewClientWithBaseURL(server.URL, required).GetGemInfo("rack", "3.0.0"); err == nil {
+		t.Fatal("expected MissingCredentialsError without WithAnonymous")
+	}
+	if _, err := NewClientWithBaseURL(server.URL, required, WithAnonymous()).GetGemInfo("rack", "3.0.0"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
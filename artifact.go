@@ -0,0 +1,352 @@
+package rubygemsclient
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// GemFileName returns the .gem file name for a version, e.g. "nokogiri-1.16.0"
+// plus "-x86_64-linux" for platform builds and ".gem". An empty or "ruby"
+// platform means the pure-Ruby build.
+func GemFileName(name, version, platform string) string {
+	file := name + "-" + version
+	if platform != "" && platform != "ruby" {
+		file += "-" + platform
+	}
+	return file + ".gem"
+}
+
+// DefaultDownloadPath is where .gem files are served on rubygems.org and
+// most gem servers, such as Gemstash and Geminabox.
+const DefaultDownloadPath = "/gems/{file}"
+
+// WithDownloadPath sets where the server serves .gem files, relative to its
+// root (the base URL without /api/v1), for servers laid out differently
+// from rubygems.org, e.g. "/downloads/{file}" or "/{name}/{version}/{file}".
+// {file} is replaced with the GemFileName, {name} and {version} with the
+// gem's name and version. The default is DefaultDownloadPath.
+func WithDownloadPath(template string) ClientOption {
+	return func(c *Client) {
+		c.downloadPath = template
+	}
+}
+
+// gemFileURL returns the URL of a .gem file under the download path.
+func (c *Client) gemFileURL(name, version, platform string) string {
+	path := strings.NewReplacer(
+		"{file}", url.PathEscape(GemFileName(name, version, platform)),
+		"{name}", url.PathEscape(name),
+		"{version}", url.PathEscape(version),
+	).Replace(cmp.Or(c.downloadPath, DefaultDownloadPath))
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return c.serverRoot() + path
+}
+
+// downloadOptions configures .gem downloads.
+type downloadOptions struct {
+	progress func(written, total int64)
+}
+
+// DownloadOption configures DownloadGem and the other .gem download methods.
+type DownloadOption func(*downloadOptions)
+
+// DownloadProgress calls fn after each chunk of the file is written, with
+// the bytes written so far and the file's size from Content-Length, or -1
+// when the server does not send one. fn runs on the downloading goroutine.
+func DownloadProgress(fn func(written, total int64)) DownloadOption {
+	return func(o *downloadOptions) {
+		o.progress = fn
+	}
+}
+
+// progressWriter reports each write to a DownloadProgress callback.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.progress(p.written, p.total)
+	return n, err
+}
+
+// DownloadGem streams the pure-Ruby .gem file for a version to w and returns
+// the number of bytes written. The file is served from the client's server
+// root, <host>/gems/<file>.gem unless WithDownloadPath says otherwise, with
+// the client's credentials.
+func (c *Client) DownloadGem(name, version string, w io.Writer, opts ...DownloadOption) (int64, error) {
+	return c.DownloadGemContext(context.Background(), name, version, w, opts...)
+}
+
+// DownloadGemContext is like DownloadGem but aborts when ctx is canceled.
+// A download canceled partway returns an error wrapping ctx.Err() along with
+// the bytes already written, so w then holds a truncated file; use
+// DownloadGemToFile to avoid leaving one where a complete gem is expected.
+func (c *Client) DownloadGemContext(ctx context.Context, name, version string, w io.Writer, opts ...DownloadOption) (int64, error) {
+	return c.DownloadGemForPlatformContext(ctx, name, version, "", w, opts...)
+}
+
+// DownloadGemForPlatform is like DownloadGem but fetches the build for
+// platform, such as "x86_64-linux" or "java".
+func (c *Client) DownloadGemForPlatform(name, version, platform string, w io.Writer, opts ...DownloadOption) (int64, error) {
+	return c.DownloadGemForPlatformContext(context.Background(), name, version, platform, w, opts...)
+}
+
+// DownloadGemForPlatformContext is like DownloadGemForPlatform but aborts when ctx is canceled.
+func (c *Client) DownloadGemForPlatformContext(
+	ctx context.Context, name, version, platform string, w io.Writer, opts ...DownloadOption,
+) (int64, error) {
+	var o downloadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	endpoint := c.gemFileURL(name, version, platform)
+
+	req, err := c.newRequest(withGemName(ctx, name), "GET", endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", MIMEMarshal)
+
+	// Skip the response cache: gems are streamed, not buffered
+	resp, err := c.send(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download gem: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, newAPIError(resp, name)
+	}
+
+	if o.progress != nil {
+		w = &progressWriter{w: w, total: resp.ContentLength, progress: o.progress}
+	}
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return n, fmt.Errorf("download of %s canceled after %d bytes: %w", GemFileName(name, version, platform), n, ctx.Err())
+		}
+		return n, fmt.Errorf("failed to download gem: %w", err)
+	}
+	return n, nil
+}
+
+// partialSuffix marks a .gem file that DownloadGemToFile is still writing.
+const partialSuffix = ".partial"
+
+// DownloadGemToFile downloads the .gem file for a version and platform to
+// path. The file is written as path + ".partial" and only renamed to path
+// once complete, so an interrupted or failed download never leaves a
+// truncated file that looks like a complete gem; the partial file is
+// removed.
+func (c *Client) DownloadGemToFile(name, version, platform, path string, opts ...DownloadOption) error {
+	return c.DownloadGemToFileContext(context.Background(), name, version, platform, path, opts...)
+}
+
+// DownloadGemToFileContext is like DownloadGemToFile but aborts when ctx is canceled.
+func (c *Client) DownloadGemToFileContext(ctx context.Context, name, version, platform, path string, opts ...DownloadOption) error {
+	partial := path + partialSuffix
+	f, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	_, err = c.DownloadGemForPlatformContext(ctx, name, version, platform, f, opts...)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partial, path)
+	}
+	if err != nil {
+		_ = os.Remove(partial)
+		return err
+	}
+	return nil
+}
+
+// GemDownload is one .gem file for DownloadGems to fetch.
+type GemDownload struct {
+	Name     string
+	Version  string
+	Platform string // "" or "ruby" for the pure-Ruby build
+	Path     string // Where the file is written, as by DownloadGemToFile
+
+	// Progress, if set, reports this file's progress like DownloadProgress.
+	Progress func(written, total int64)
+}
+
+// DownloadGems downloads each file to its Path like DownloadGemToFile, in
+// parallel within the WithConcurrency limit. opts apply to every file; a
+// download's own Progress replaces any DownloadProgress among them and may
+// be called from several goroutines at once across files. Files that fail
+// are left absent and the error joins their failures, each prefixed with
+// the file name.
+func (c *Client) DownloadGems(downloads []GemDownload, opts ...DownloadOption) error {
+	return c.DownloadGemsContext(context.Background(), downloads, opts...)
+}
+
+// DownloadGemsContext is like DownloadGems but aborts when ctx is canceled.
+func (c *Client) DownloadGemsContext(ctx context.Context, downloads []GemDownload, opts ...DownloadOption) error {
+	errs := make([]error, len(downloads))
+	var wg sync.WaitGroup
+
+	for i, d := range downloads {
+		wg.Go(func() {
+			release, err := c.acquireSlot(ctx)
+			if err == nil {
+				defer release()
+				fileOpts := opts
+				if d.Progress != nil {
+					fileOpts = append(slices.Clip(opts), DownloadProgress(d.Progress))
+				}
+				err = c.DownloadGemToFileContext(ctx, d.Name, d.Version, d.Platform, d.Path, fileOpts...)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", GemFileName(d.Name, d.Version, d.Platform), err)
+			}
+		})
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// DownloadGemVerified is like DownloadGem but hashes the file while streaming
+// it and returns a *ChecksumMismatchError if its SHA-256 differs from
+// expectedSHA256 (hex, as listed in the compact index). The bytes have
+// already been written to w by then, so callers should write to a temporary
+// location and discard it on error.
+func (c *Client) DownloadGemVerified(name, version, expectedSHA256 string, w io.Writer, opts ...DownloadOption) error {
+	return c.DownloadGemVerifiedContext(context.Background(), name, version, expectedSHA256, w, opts...)
+}
+
+// DownloadGemVerifiedContext is like DownloadGemVerified but aborts when ctx is canceled.
+func (c *Client) DownloadGemVerifiedContext(
+	ctx context.Context, name, version, expectedSHA256 string, w io.Writer, opts ...DownloadOption,
+) error {
+	return c.DownloadGemVerifiedForPlatformContext(ctx, name, version, "", expectedSHA256, w, opts...)
+}
+
+// DownloadGemVerifiedForPlatform is like DownloadGemVerified but fetches the
+// build for platform, such as "x86_64-linux" or "java". Each platform build
+// has its own checksum.
+func (c *Client) DownloadGemVerifiedForPlatform(name, version, platform, expected string, w io.Writer, opts ...DownloadOption) error {
+	return c.DownloadGemVerifiedForPlatformContext(context.Background(), name, version, platform, expected, w, opts...)
+}
+
+// DownloadGemVerifiedForPlatformContext is like DownloadGemVerifiedForPlatform but aborts when ctx is canceled.
+func (c *Client) DownloadGemVerifiedForPlatformContext(
+	ctx context.Context, name, version, platform, expected string, w io.Writer, opts ...DownloadOption,
+) error {
+	hash := sha256.New()
+	if _, err := c.DownloadGemForPlatformContext(ctx, name, version, platform, io.MultiWriter(w, hash), opts...); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	if actual != expected {
+		return &ChecksumMismatchError{
+			File:     GemFileName(name, version, platform),
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+	return nil
+}
+
+// FetchAndVerify downloads the .gem file for a version and platform to w,
+// checking it against the SHA-256 the server's compact index lists for that
+// build. An empty or "ruby" platform means the pure-Ruby build. It returns
+// ErrNoMatchingVersion if the index has no such build and a
+// *ChecksumMismatchError if the download differs; as with
+// DownloadGemVerified, w has received the bytes by then.
+func (c *Client) FetchAndVerify(name, version, platform string, w io.Writer, opts ...DownloadOption) error {
+	return c.FetchAndVerifyContext(context.Background(), name, version, platform, w, opts...)
+}
+
+// FetchAndVerifyContext is like FetchAndVerify but aborts when ctx is canceled.
+func (c *Client) FetchAndVerifyContext(ctx context.Context, name, version, platform string, w io.Writer, opts ...DownloadOption) error {
+	versions, err := NewCompactIndexClient(c).GetInfoContext(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	want := platform
+	if want == string(PlatformRuby) {
+		want = ""
+	}
+	for _, v := range versions {
+		if v.Version != version || v.Platform != want {
+			continue
+		}
+		if v.Checksum == "" {
+			return fmt.Errorf("compact index lists no checksum for %s", GemFileName(name, version, platform))
+		}
+		return c.DownloadGemVerifiedForPlatformContext(ctx, name, version, platform, v.Checksum, w, opts...)
+	}
+	return fmt.Errorf("%s: %w", GemFileName(name, version, platform), ErrNoMatchingVersion)
+}
+
+// GemExists reports whether the pure-Ruby .gem file for a version is
+// published, using a HEAD request so nothing is downloaded. It is much
+// cheaper than GetGemInfo for checking many pinned versions. Responses other
+// than 200 and 404 are returned as an *APIError.
+func (c *Client) GemExists(name, version string) (bool, error) {
+	return c.GemExistsContext(context.Background(), name, version)
+}
+
+// GemExistsContext is like GemExists but aborts when ctx is canceled.
+func (c *Client) GemExistsContext(ctx context.Context, name, version string) (bool, error) {
+	return c.GemExistsForPlatformContext(ctx, name, version, "")
+}
+
+// GemExistsForPlatform is like GemExists but checks the build for platform,
+// such as "x86_64-linux" or "java".
+func (c *Client) GemExistsForPlatform(name, version, platform string) (bool, error) {
+	return c.GemExistsForPlatformContext(context.Background(), name, version, platform)
+}
+
+// GemExistsForPlatformContext is like GemExistsForPlatform but aborts when ctx is canceled.
+func (c *Client) GemExistsForPlatformContext(ctx context.Context, name, version, platform string) (bool, error) {
+	endpoint := c.gemFileURL(name, version, platform)
+
+	req, err := c.newRequest(withGemName(ctx, name), http.MethodHead, endpoint)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to check gem: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, newAPIError(resp, name)
+	}
+}
@@ -0,0 +1,468 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGemFileName(t *testing.T) {
+	tests := []struct {
+		platform string
+		want     string
+	}{
+		{"", "nokogiri-1.16.0.gem"},
+		{"ruby", "nokogiri-1.16.0.gem"},
+		{"x86_64-linux", "nokogiri-1.16.0-x86_64-linux.gem"},
+	}
+	for _, tt := range tests {
+		if got := GemFileName("nokogiri", "1.16.0", tt.platform); got != tt.want {
+			t.Errorf("GemFileName(%q) = %q, want %q", tt.platform, got, tt.want)
+		}
+	}
+}
+
+func newArtifactServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string]string{
+		"/gems/rack-3.0.0.gem":         "ruby build",
+		"/gems/rack-3.0.0-java.gem":    "java build",
+		"/private/gems/rack-3.0.0.gem": "private build",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Path == "/private/gems/rack-3.0.0.gem" && r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadGem(t *testing.T) {
+	server := newArtifactServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	var buf bytes.Buffer
+	n, err := client.DownloadGem("rack", "3.0.0", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "ruby build" || n != int64(buf.Len()) {
+		t.Errorf("got %q (%d bytes)", buf.String(), n)
+	}
+
+	buf.Reset()
+	if _, err := client.DownloadGemForPlatform("rack", "3.0.0", "java", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "java build" {
+		t.Errorf("platform download got %q", buf.String())
+	}
+
+	if _, err := client.DownloadGem("rack", "9.9.9", &buf); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+}
+
+func TestDownloadGem_Progress(t *testing.T) {
+	body := strings.Repeat("x", 100_000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gems/rack-3.0.0-java.gem" {
+			// Chunked: no Content-Length
+			w.(http.Flusher).Flush()
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+
+	tests := []struct {
+		platform  string
+		wantTotal int64
+	}{
+		{"", int64(len(body))},
+		{"java", -1},
+	}
+	for _, tt := range tests {
+		var calls int
+		var last, total int64
+		progress := DownloadProgress(func(written, size int64) {
+			if written < last {
+				t.Errorf("written went backwards: %d after %d", written, last)
+			}
+			calls++
+			last, total = written, size
+		})
+
+		n, err := client.DownloadGemForPlatform("rack", "3.0.0", tt.platform, io.Discard, progress)
+		if err != nil {
+			t.Fatalf("platform %q: unexpected error: %v", tt.platform, err)
+		}
+		if calls == 0 || last != n || last != int64(len(body)) {
+			t.Errorf("platform %q: %d calls, last written %d, want %d", tt.platform, calls, last, len(body))
+		}
+		if total != tt.wantTotal {
+			t.Errorf("platform %q: total = %d, want %d", tt.platform, total, tt.wantTotal)
+		}
+	}
+}
+
+// newStallingServer serves half of a .gem file and then stalls until the
+// client goes away.
+func newStallingServer(t *testing.T, size int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gems/rack-1.0.0.gem" {
+			_, _ = w.Write([]byte("complete"))
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		_, _ = w.Write(bytes.Repeat([]byte("x"), size/2))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadGem_CanceledMidStream(t *testing.T) {
+	const size = 64 << 10
+	server := newStallingServer(t, size)
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelOnData := DownloadProgress(func(written, total int64) { cancel() })
+
+	var buf bytes.Buffer
+	n, err := client.DownloadGemContext(ctx, "rack", "3.0.0", &buf, cancelOnData)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if n == 0 || n >= size || n != int64(buf.Len()) {
+		t.Errorf("expected a partial count of the bytes written, got %d of %d (buffer %d)", n, size, buf.Len())
+	}
+}
+
+func TestDownloadGemToFile(t *testing.T) {
+	const size = 64 << 10
+	server := newStallingServer(t, size)
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+	dir := t.TempDir()
+
+	complete := filepath.Join(dir, "rack-1.0.0.gem")
+	if err := client.DownloadGemToFile("rack", "1.0.0", "", complete); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(complete); string(data) != "complete" {
+		t.Errorf("got %q", data)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	canceled := filepath.Join(dir, "rack-3.0.0.gem")
+	err := client.DownloadGemToFileContext(ctx, "rack", "3.0.0", "", canceled, DownloadProgress(func(int64, int64) { cancel() }))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	// Neither the gem nor a partial file is left behind
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "rack-1.0.0.gem" {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("expected only the complete gem, got %v", names)
+	}
+}
+
+func TestDownloadGems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gems/missing-1.0.0.gem" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(strings.TrimPrefix(r.URL.Path, "/gems/")))
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+	dir := t.TempDir()
+
+	var mu sync.Mutex
+	written := make(map[string]int64)
+	progressFor := func(file string) func(int64, int64) {
+		return func(n, _ int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			written[file] = n
+		}
+	}
+	downloads := []GemDownload{
+		{Name: "rack", Version: "3.0.0", Path: filepath.Join(dir, "rack.gem"), Progress: progressFor("rack")},
+		{Name: "nokogiri", Version: "1.16.0", Platform: "x86_64-linux", Path: filepath.Join(dir, "nokogiri.gem"),
+			Progress: progressFor("nokogiri")},
+		{Name: "missing", Version: "1.0.0", Path: filepath.Join(dir, "missing.gem")},
+	}
+
+	err := client.DownloadGems(downloads)
+	if !errors.Is(err, ErrGemNotFound) || !strings.Contains(err.Error(), "missing-1.0.0.gem") {
+		t.Errorf("expected the missing gem's error, got %v", err)
+	}
+
+	for file, want := range map[string]string{"rack": "rack-3.0.0.gem", "nokogiri": "nokogiri-1.16.0-x86_64-linux.gem"} {
+		data, err := os.ReadFile(filepath.Join(dir, file+".gem"))
+		if err != nil || string(data) != want {
+			t.Errorf("%s: got %q, %v", file, data, err)
+		}
+		if written[file] != int64(len(want)) {
+			t.Errorf("%s: progress reported %d bytes, want %d", file, written[file], len(want))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.gem")); !os.IsNotExist(err) {
+		t.Errorf("expected no file for the failed download, got %v", err)
+	}
+}
+
+func TestDownloadGem_Credentials(t *testing.T) {
+	server := newArtifactServer(t)
+
+	anonymous := NewClientWithBaseURL(server.URL + "/private")
+	if _, err := anonymous.DownloadGem("rack", "3.0.0", &bytes.Buffer{}); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without credentials, got %v", err)
+	}
+
+	client := NewClientWithBaseURL(server.URL+"/private", WithCredentials(&Credentials{Token: "secret"}))
+	var buf bytes.Buffer
+	if _, err := client.DownloadGem("rack", "3.0.0", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "private build" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestDownloadGemVerified(t *testing.T) {
+	server := newArtifactServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	sum := sha256.Sum256([]byte("ruby build"))
+	good := hex.EncodeToString(sum[:])
+
+	var buf bytes.Buffer
+	if err := client.DownloadGemVerified("rack", "3.0.0", good, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "ruby build" {
+		t.Errorf("got %q", buf.String())
+	}
+
+	// Checksums compare case-insensitively
+	if err := client.DownloadGemVerified("rack", "3.0.0", strings.ToUpper(good), &bytes.Buffer{}); err != nil {
+		t.Errorf("uppercase checksum: unexpected error: %v", err)
+	}
+
+	bad := strings.Repeat("0", 64)
+	err := client.DownloadGemVerified("rack", "3.0.0", bad, &bytes.Buffer{})
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected *ChecksumMismatchError, got %v", err)
+	}
+	if mismatch.Expected != bad || mismatch.Actual != good || mismatch.File != "rack-3.0.0.gem" {
+		t.Errorf("unexpected mismatch details: %+v", mismatch)
+	}
+}
+
+func TestDownloadGemVerifiedForPlatform(t *testing.T) {
+	server := newArtifactServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	sum := sha256.Sum256([]byte("java build"))
+	good := hex.EncodeToString(sum[:])
+
+	var buf bytes.Buffer
+	if err := client.DownloadGemVerifiedForPlatform("rack", "3.0.0", "java", good, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "java build" {
+		t.Errorf("got %q", buf.String())
+	}
+
+	// The ruby build's checksum does not match the java build
+	rubySum := sha256.Sum256([]byte("ruby build"))
+	err := client.DownloadGemVerifiedForPlatform("rack", "3.0.0", "java", hex.EncodeToString(rubySum[:]), &bytes.Buffer{})
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected *ChecksumMismatchError, got %v", err)
+	}
+	if mismatch.File != "rack-3.0.0-java.gem" || mismatch.Actual != good {
+		t.Errorf("unexpected mismatch details: %+v", mismatch)
+	}
+}
+
+func TestFetchAndVerify(t *testing.T) {
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	info := "---\n" +
+		"3.0.0 |checksum:" + sum("ruby build") + "\n" +
+		"3.0.0-java |checksum:" + sum("the java build") + "\n" +
+		"2.0.0 \n"
+	files := map[string]string{
+		"/info/rack":                info,
+		"/gems/rack-3.0.0.gem":      "ruby build",
+		"/gems/rack-3.0.0-java.gem": "java build",
+		"/gems/rack-2.0.0.gem":      "old build",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL(server.URL)
+
+	for _, platform := range []string{"", "ruby"} {
+		var buf bytes.Buffer
+		if err := client.FetchAndVerify("rack", "3.0.0", platform, &buf); err != nil {
+			t.Fatalf("platform %q: unexpected error: %v", platform, err)
+		}
+		if buf.String() != "ruby build" {
+			t.Errorf("platform %q: got %q", platform, buf.String())
+		}
+	}
+
+	// The index lists a different checksum for the java build
+	err := client.FetchAndVerify("rack", "3.0.0", "java", &bytes.Buffer{})
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected *ChecksumMismatchError, got %v", err)
+	}
+	if mismatch.File != "rack-3.0.0-java.gem" || mismatch.Expected != sum("the java build") {
+		t.Errorf("unexpected mismatch details: %+v", mismatch)
+	}
+
+	if err := client.FetchAndVerify("rack", "3.0.0", "x86_64-linux", &bytes.Buffer{}); !errors.Is(err, ErrNoMatchingVersion) {
+		t.Errorf("expected ErrNoMatchingVersion for an unlisted platform, got %v", err)
+	}
+	if err := client.FetchAndVerify("rack", "2.0.0", "", &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for a version listed without a checksum")
+	}
+	if err := client.FetchAndVerify("missing", "1.0.0", "", &bytes.Buffer{}); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+}
+
+func TestWithDownloadPath(t *testing.T) {
+	files := map[string]string{
+		"/downloads/rack-3.0.0-java.gem":  "downloads layout",
+		"/repo/rack/3.0.0/rack-3.0.0.gem": "nested layout",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name, baseURL, template, platform, want string
+	}{
+		{"downloads", server.URL, "/downloads/{file}", "java", "downloads layout"},
+		{"nested under a base path", server.URL + "/repo", "{name}/{version}/{file}", "", "nested layout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithBaseURL(tt.baseURL, WithDownloadPath(tt.template))
+
+			var buf bytes.Buffer
+			if _, err := client.DownloadGemForPlatform("rack", "3.0.0", tt.platform, &buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+
+			exists, err := client.GemExistsForPlatform("rack", "3.0.0", tt.platform)
+			if err != nil || !exists {
+				t.Errorf("GemExistsForPlatform = %v, %v; want true", exists, err)
+			}
+		})
+	}
+
+	// The default layout is not served here
+	if _, err := NewClientWithBaseURL(server.URL).DownloadGem("rack", "3.0.0", io.Discard); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound with the default layout, got %v", err)
+	}
+}
+
+func TestGemExists(t *testing.T) {
+	server := newArtifactServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	tests := []struct {
+		version, platform string
+		want              bool
+	}{
+		{"3.0.0", "", true},
+		{"3.0.0", "java", true},
+		{"3.0.0", "x86_64-linux", false},
+		{"9.9.9", "", false},
+	}
+	for _, tt := range tests {
+		got, err := client.GemExistsForPlatform("rack", tt.version, tt.platform)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GemExistsForPlatform(%q, %q) = %v, want %v", tt.version, tt.platform, got, tt.want)
+		}
+	}
+
+	private := NewClientWithBaseURL(server.URL + "/private")
+	if _, err := private.GemExists("rack", "3.0.0"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without credentials, got %v", err)
+	}
+}
+
+func TestGemExists_UsesHEAD(t *testing.T) {
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+	}))
+	defer server.Close()
+
+	if ok, err := NewClientWithBaseURL(server.URL).GemExists("rack", "3.0.0"); err != nil || !ok {
+		t.Fatalf("GemExists() = %v, %v", ok, err)
+	}
+	if method != http.MethodHead {
+		t.Errorf("method = %s, want HEAD", method)
+	}
+}
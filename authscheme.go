@@ -0,0 +1,77 @@
+package rubygemsclient
+
+import "net/http"
+
+// AuthScheme selects how credentials are sent to a host.
+type AuthScheme int
+
+const (
+	// AuthSchemeAuto sends tokens as Bearer and username/password pairs as
+	// Basic auth. This is the default.
+	AuthSchemeAuto AuthScheme = iota
+	// AuthSchemeBearer sends the token, or the password of a username/password
+	// pair, as a Bearer token.
+	AuthSchemeBearer
+	// AuthSchemeBasic always uses Basic auth. A token is sent as
+	// "<token>:x-oauth-basic", the form GitHub Packages and Gemfury accept.
+	AuthSchemeBasic
+)
+
+// basicTokenPassword is the password sent with a token under AuthSchemeBasic.
+const basicTokenPassword = "x-oauth-basic"
+
+// String returns the scheme's name.
+func (s AuthScheme) String() string {
+	switch s {
+	case AuthSchemeBearer:
+		return "bearer"
+	case AuthSchemeBasic:
+		return "basic"
+	default:
+		return "auto"
+	}
+}
+
+// WithAuthScheme overrides how credentials are sent to host, for servers that
+// only accept one form. Hosts are matched case-insensitively, ignoring any
+// port, against the server that receives the request (the mirror, if one is
+// set). The option can be repeated for several hosts.
+func WithAuthScheme(host string, scheme AuthScheme) ClientOption {
+	return func(c *Client) {
+		if c.authSchemes == nil {
+			c.authSchemes = make(map[string]AuthScheme)
+		}
+		c.authSchemes[normalizeHost(host)] = scheme
+	}
+}
+
+// applyAuth adds authentication headers for creds to the request, if any,
+// in the form WithAuthScheme sets for the receiving host.
+func (c *Client) applyAuth(req *http.Request, creds *Credentials) {
+	if creds == nil {
+		return
+	}
+
+	token := creds.GetToken()
+	switch c.authSchemes[normalizeHost(req.URL.Host)] {
+	case AuthSchemeBasic:
+		if token != "" {
+			req.SetBasicAuth(token, basicTokenPassword)
+			return
+		}
+	case AuthSchemeBearer:
+		if token == "" {
+			token = creds.Password
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return
+		}
+	}
+
+	if creds.IsToken() {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if creds.Username != "" {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+}
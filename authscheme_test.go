@@ -0,0 +1,89 @@
+package rubygemsclient
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithAuthScheme(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"name":"rack","version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	basic := func(user, pass string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+pass))
+	}
+	token := &Credentials{Token: "ghp_secret"}
+	anyToken := &Credentials{Username: "any", Password: "ghp_secret"}
+	userPass := &Credentials{Username: "alice", Password: "hunter2"}
+
+	tests := []struct {
+		name   string
+		creds  *Credentials
+		host   string
+		scheme AuthScheme
+		want   string
+	}{
+		{"token auto", token, "127.0.0.1", AuthSchemeAuto, "Bearer ghp_secret"},
+		{"token basic", token, "127.0.0.1", AuthSchemeBasic, basic("ghp_secret", "x-oauth-basic")},
+		{"any username basic", anyToken, "127.0.0.1", AuthSchemeBasic, basic("ghp_secret", "x-oauth-basic")},
+		{"token bearer", token, "127.0.0.1", AuthSchemeBearer, "Bearer ghp_secret"},
+		{"password auto", userPass, "127.0.0.1", AuthSchemeAuto, basic("alice", "hunter2")},
+		{"password basic", userPass, "127.0.0.1", AuthSchemeBasic, basic("alice", "hunter2")},
+		{"password bearer", userPass, "127.0.0.1", AuthSchemeBearer, "Bearer hunter2"},
+		{"other host", token, "gems.example.com", AuthSchemeBasic, "Bearer ghp_secret"},
+		{"host with port", token, "127.0.0.1:1", AuthSchemeBasic, basic("ghp_secret", "x-oauth-basic")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithBaseURL(server.URL, WithCredentials(tt.creds), WithAuthScheme(tt.host, tt.scheme))
+			if _, err := client.GetGemInfo("rack", "3.0.0"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAuthScheme_RedactsBasicToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprintf(w, "token %s denied", user)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL,
+		WithCredentials(&Credentials{Token: "ghp_secret"}),
+		WithAuthScheme("127.0.0.1", AuthSchemeBasic))
+
+	_, err := client.GetGemInfo("private-gem", "1.0.0")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *APIError, got %v", err)
+	}
+	if apiErr.Body != "token **** denied" {
+		t.Errorf("Expected masked token in body, got %q", apiErr.Body)
+	}
+}
+
+func TestAuthScheme_String(t *testing.T) {
+	for scheme, want := range map[AuthScheme]string{
+		AuthSchemeAuto:   "auto",
+		AuthSchemeBearer: "bearer",
+		AuthSchemeBasic:  "basic",
+	} {
+		if got := scheme.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", scheme, got, want)
+		}
+	}
+}
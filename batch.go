@@ -0,0 +1,61 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BatchResult is the outcome of a batch lookup, in request order. It is a
+// plain slice, so a []GemInfoResult from GetMultipleGemInfo converts
+// directly: BatchResult(client.GetMultipleGemInfo(requests)).
+type BatchResult []GemInfoResult
+
+// GetMultipleGemInfoBatch is like GetMultipleGemInfo but returns a
+// BatchResult.
+func (c *Client) GetMultipleGemInfoBatch(requests []GemInfoRequest) BatchResult {
+	return c.GetMultipleGemInfoBatchContext(context.Background(), requests)
+}
+
+// GetMultipleGemInfoBatchContext is like GetMultipleGemInfoBatch but aborts
+// when ctx is canceled.
+func (c *Client) GetMultipleGemInfoBatchContext(ctx context.Context, requests []GemInfoRequest) BatchResult {
+	return BatchResult(c.GetMultipleGemInfoContext(ctx, requests))
+}
+
+// Successes returns the results without an error, in request order.
+func (b BatchResult) Successes() []GemInfoResult {
+	var ok []GemInfoResult
+	for _, r := range b {
+		if r.Error == nil {
+			ok = append(ok, r)
+		}
+	}
+	return ok
+}
+
+// Failures returns the results with an error, in request order.
+func (b BatchResult) Failures() []GemInfoResult {
+	var failed []GemInfoResult
+	for _, r := range b {
+		if r.Error != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Err joins every failure with errors.Join, each prefixed with the gem it
+// was for, or returns nil if all requests succeeded. errors.Is and errors.As
+// see through it to the individual errors.
+func (b BatchResult) Err() error {
+	var errs []error
+	for _, r := range b.Failures() {
+		name := r.Request.Name
+		if r.Request.Version != "" {
+			name += " " + r.Request.Version
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, r.Error))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatchResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem", Version: "1.0.0"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+	requests := []GemInfoRequest{
+		{Name: "rails"},
+		{Name: "missing-one", Version: "1.0.0"},
+		{Name: "puma"},
+		{Name: "missing-two"},
+	}
+	batch := client.GetMultipleGemInfoBatch(requests)
+
+	if len(batch) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(batch), len(requests))
+	}
+	for i, r := range batch {
+		if r.Request != requests[i] {
+			t.Errorf("result %d is for %+v, want %+v", i, r.Request, requests[i])
+		}
+	}
+
+	successes := batch.Successes()
+	if len(successes) != 2 || successes[0].Request.Name != "rails" || successes[1].Request.Name != "puma" {
+		t.Errorf("Successes() = %+v", successes)
+	}
+	failures := batch.Failures()
+	if len(failures) != 2 || failures[0].Request.Name != "missing-one" || failures[1].Request.Name != "missing-two" {
+		t.Errorf("Failures() = %+v", failures)
+	}
+
+	err := batch.Err()
+	if !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("Err() = %v, want it to match ErrGemNotFound", err)
+	}
+	for _, want := range []string{"missing-one 1.0.0:", "missing-two:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Err() = %q, want it to mention %q", err, want)
+		}
+	}
+
+	if err := BatchResult(client.GetMultipleGemInfo(requests[:1])).Err(); err != nil {
+		t.Errorf("all-success Err() = %v, want nil", err)
+	}
+}
@@ -2,59 +2,126 @@ package rubygemsclient
 
 import (
 	"bufio"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+
+	"gopkg.in/yaml.v3"
 )
 
-// BundleConfig holds parsed credentials from a single .bundle/config file.
-// It caches credentials keyed by BUNDLE_<HOST> format.
+// BundleConfig holds parsed credentials and settings from a single
+// .bundle/config file. Credentials are keyed by BUNDLE_<HOST> format; every
+// other key (BUNDLE_PATH, BUNDLE_MIRROR__<URI>, ...) is a plain setting.
 type BundleConfig struct {
 	credentials map[string]*Credentials
+	settings    map[string]string
 }
 
-var (
-	localConfig      *BundleConfig
-	globalConfig     *BundleConfig
-	configLoadedOnce sync.Once
-)
+// mirrorKeyPrefix starts config keys that configure a source mirror.
+const mirrorKeyPrefix = "BUNDLE_MIRROR__"
+
+// configState is the process-wide credential state behind the package-level
+// functions: the default resolver, ~/.gem/credentials and .netrc. Each is
+// loaded on first use; all access goes through mu so lookups are safe to run
+// concurrently with ReloadConfigs.
+type configState struct {
+	mu sync.Mutex
+
+	resolver *ConfigResolver
+
+	gemCredentials       *GemCredentials
+	gemCredentialsLoaded bool
+
+	netrcMachines map[string]netrcMachine
+	netrcLoaded   bool
+}
+
+var configs configState
 
 // ResetConfigCache clears the cached config for testing purposes.
 // This should only be used in tests.
 func ResetConfigCache() {
-	localConfig = nil
-	globalConfig = nil
-	configLoadedOnce = sync.Once{}
+	configs.mu.Lock()
+	defer configs.mu.Unlock()
+	configs.reset()
 }
 
-// loadConfigs loads both local and global configs separately.
-func loadConfigs() {
-	// Load local config (.bundle/config)
-	localPath := ".bundle/config"
-	if data, err := os.ReadFile(localPath); err == nil {
-		localConfig = parseConfigFile(data)
+// ReloadConfigs discards every cached credential file (.bundle/config,
+// ~/.gem/credentials, .netrc) and reads the Bundler configs again, so a
+// long-running process picks up edited settings and rotated tokens. Clients
+// resolve credentials when they are created; build new ones after a reload.
+// See WatchConfigs to reload automatically. Lookups running at the same time
+// see either the old or the new configs, never a mix.
+func ReloadConfigs() {
+	resolver := newDefaultResolver()
+
+	configs.mu.Lock()
+	defer configs.mu.Unlock()
+	configs.reset()
+	configs.resolver = resolver
+}
+
+// reset drops all loaded state. The caller must hold s.mu.
+func (s *configState) reset() {
+	s.resolver = nil
+	s.gemCredentials, s.gemCredentialsLoaded = nil, false
+	s.netrcMachines, s.netrcLoaded = nil, false
+}
+
+// newDefaultResolver builds a resolver from the working directory and
+// environment: .bundle/config (or $BUNDLE_APP_CONFIG/config) and
+// ~/.bundle/config.
+func newDefaultResolver() *ConfigResolver {
+	return NewConfigResolver(localBundleConfigPath(), globalBundleConfigPath())
+}
+
+// defaultConfigResolver returns the resolver behind the package-level
+// functions, loading it on first use.
+func defaultConfigResolver() *ConfigResolver {
+	configs.mu.Lock()
+	defer configs.mu.Unlock()
+	if configs.resolver == nil {
+		configs.resolver = newDefaultResolver()
 	}
+	return configs.resolver
+}
 
-	// Load global config (~/.bundle/config)
-	if globalPath := globalBundleConfigPath(); globalPath != "" {
-		if data, err := os.ReadFile(globalPath); err == nil {
-			globalConfig = parseConfigFile(data)
-		}
+// readConfigFile reads and parses one config file. A missing or empty path,
+// an unreadable file, or a file with no entries gives nil.
+func readConfigFile(path string) *BundleConfig {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
 	}
+	return parseConfigFile(data)
 }
 
 // parseConfigFile parses a single config file into a BundleConfig.
 func parseConfigFile(data []byte) *BundleConfig {
 	config := &BundleConfig{
 		credentials: make(map[string]*Credentials),
+		settings:    make(map[string]string),
 	}
 	for k, v := range parseBundleConfigYAML(data) {
+		credential, setting := classifyConfigKey(k)
+		if setting {
+			config.settings[k] = v
+		}
+		if !credential {
+			continue
+		}
 		if creds := parseCredentialValue(v); creds != nil {
 			config.credentials[k] = creds
 		}
 	}
-	if len(config.credentials) == 0 {
+	if len(config.credentials) == 0 && len(config.settings) == 0 {
 		return nil
 	}
 	return config
@@ -62,28 +129,26 @@ func parseConfigFile(data []byte) *BundleConfig {
 
 // GetLocalBundleConfig returns credentials from .bundle/config (project-local).
 func GetLocalBundleConfig() *BundleConfig {
-	configLoadedOnce.Do(loadConfigs)
-	return localConfig
+	return defaultConfigResolver().LocalConfig()
 }
 
 // GetGlobalBundleConfig returns credentials from ~/.bundle/config (user global).
 func GetGlobalBundleConfig() *BundleConfig {
-	configLoadedOnce.Do(loadConfigs)
-	return globalConfig
+	return defaultConfigResolver().GlobalConfig()
 }
 
 // LoadBundleConfig loads and merges both config files for backwards compatibility.
 // Priority: local (.bundle/config) > global (~/.bundle/config)
 // Note: Prefer using CredentialsFor() which has the correct Bundler priority order.
 func LoadBundleConfig() *BundleConfig {
-	configLoadedOnce.Do(loadConfigs)
-
+	localConfig, globalConfig := GetLocalBundleConfig(), GetGlobalBundleConfig()
 	if localConfig == nil && globalConfig == nil {
 		return nil
 	}
 
 	merged := &BundleConfig{
 		credentials: make(map[string]*Credentials),
+		settings:    make(map[string]string),
 	}
 
 	// Global first (lower priority)
@@ -91,6 +156,9 @@ func LoadBundleConfig() *BundleConfig {
 		for k, v := range globalConfig.credentials {
 			merged.credentials[k] = v
 		}
+		for k, v := range globalConfig.settings {
+			merged.settings[k] = v
+		}
 	}
 
 	// Local second (overwrites global)
@@ -98,6 +166,9 @@ func LoadBundleConfig() *BundleConfig {
 		for k, v := range localConfig.credentials {
 			merged.credentials[k] = v
 		}
+		for k, v := range localConfig.settings {
+			merged.settings[k] = v
+		}
 	}
 
 	return merged
@@ -112,6 +183,151 @@ func (c *BundleConfig) CredentialsForHost(host string) *Credentials {
 	return c.credentials[envKey]
 }
 
+// credentialsForKey returns credentials stored under an exact BUNDLE_* key.
+func (c *BundleConfig) credentialsForKey(key string) *Credentials {
+	if c == nil {
+		return nil
+	}
+	return c.credentials[key]
+}
+
+// mirrorForKey returns the mirror stored under an exact BUNDLE_MIRROR__* key.
+func (c *BundleConfig) mirrorForKey(key string) string {
+	if c == nil {
+		return ""
+	}
+	return c.settings[key]
+}
+
+// Setting returns the value of a non-credential setting. name is either a
+// full key ("BUNDLE_PATH") or Bundler's dotted name ("path", "gem.push_key").
+func (c *BundleConfig) Setting(name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	value, ok := c.settings[settingKey(name)]
+	return value, ok
+}
+
+// Setting returns a Bundler setting such as "path", "jobs" or
+// "gem.push_key", using Bundler's priority: local config, then the
+// environment, then global config. Keys that can only name a credential host
+// are never returned; use CredentialsFor for those.
+func Setting(name string) (string, bool) {
+	return defaultConfigResolver().Setting(name)
+}
+
+// settingKey converts a Bundler setting name to its BUNDLE_* key the way
+// Bundler does: "gem.push_key" becomes BUNDLE_GEM__PUSH_KEY. Names that are
+// already keys are returned unchanged.
+func settingKey(name string) string {
+	if strings.HasPrefix(name, "BUNDLE_") {
+		return name
+	}
+	key := strings.ReplaceAll(name, ".", "__")
+	key = strings.ReplaceAll(key, "-", "___")
+	return "BUNDLE_" + strings.ToUpper(key)
+}
+
+// settingNamespaces are the dotted Bundler settings whose second part is a
+// gem name: build.nokogiri, local.rack.
+var settingNamespaces = map[string]bool{
+	"BUILD": true,
+	"LOCAL": true,
+}
+
+// gemSettings are the gem.* settings used by "bundle gem" and "rake release".
+var gemSettings = map[string]bool{
+	"BUNDLE": true, "CHANGELOG": true, "CI": true, "COC": true, "LINTER": true,
+	"MIT": true, "PUSH_KEY": true, "RUBOCOP": true, "TEST": true,
+}
+
+// classifyConfigKey reports whether a BUNDLE_* key holds credentials, a
+// setting, or (when Bundler's shared namespace makes it ambiguous) both. A
+// key holds credentials when it is URI-scoped, names localhost or an IPv6
+// address, or decodes to a hostname with a dot (BUNDLE_GEMS__EXAMPLE__COM).
+// BUNDLE_BUILD__NOKOGIRI could be build.nokogiri or a host of that name, so
+// it is kept as both; mirror and gem.* keys are always settings.
+func classifyConfigKey(key string) (credential, setting bool) {
+	name, ok := strings.CutPrefix(key, "BUNDLE_")
+	if !ok || name == "" || strings.HasPrefix(name, "MIRROR__") {
+		return false, true
+	}
+	if strings.Contains(name, ":") || name == "LOCALHOST" {
+		return true, false
+	}
+
+	namespace, rest, ok := strings.Cut(name, "__")
+	if !ok || namespace == "GEM" && gemSettings[rest] {
+		return false, true
+	}
+
+	host := strings.ReplaceAll(name, "___", "-")
+	host = strings.ReplaceAll(host, "__", ".")
+	for _, r := range host {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+			return false, true
+		}
+	}
+	return true, settingNamespaces[namespace] && !strings.Contains(rest, "__")
+}
+
+// localBundleConfigPath returns the path to the project's app config.
+// Like Bundler, $BUNDLE_APP_CONFIG replaces the .bundle directory entirely
+// rather than adding another layer; relative values resolve against the
+// working directory. Otherwise the nearest .bundle/config at or above the
+// working directory is used (see FindBundleConfig), falling back to
+// ./.bundle/config when there is none.
+func localBundleConfigPath() string {
+	if appConfig := os.Getenv("BUNDLE_APP_CONFIG"); appConfig != "" {
+		return filepath.Join(appConfig, "config")
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if path, err := FindBundleConfig(wd); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(".bundle", "config")
+}
+
+// FindBundleConfig looks for .bundle/config in startDir and then each parent
+// directory, as Bundler does when run from inside a project. The search stops
+// after a directory containing .git (the repository root) or at the
+// filesystem root, and never returns the global config
+// (~/.bundle/config). If nothing is found the error wraps fs.ErrNotExist.
+func FindBundleConfig(startDir string) (string, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", err
+	}
+	global := globalBundleConfigPath()
+	if global != "" {
+		if abs, err := filepath.Abs(global); err == nil {
+			global = abs
+		}
+	}
+
+	for {
+		path := filepath.Join(dir, ".bundle", "config")
+		if path != global {
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				return path, nil
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return "", fmt.Errorf("no .bundle/config found from %s: %w", startDir, fs.ErrNotExist)
+}
+
 // globalBundleConfigPath returns the path to the global .bundle/config.
 // Checks: $BUNDLE_USER_HOME/.bundle/config, $HOME/.bundle/config
 func globalBundleConfigPath() string {
@@ -128,15 +344,114 @@ func globalBundleConfigPath() string {
 	return ""
 }
 
-// parseBundleConfigYAML parses Bundler's simple YAML config format.
-// The format is:
+// parseBundleConfigYAML parses a Bundler config file. The files Bundler
+// writes are flat:
 //
 //	---
 //	BUNDLE_KEY: "value"
 //	BUNDLE_OTHER_KEY: "other_value"
 //
+// but hand-edited ones may use any YAML: block scalars, anchors and merge
+// keys are resolved, nested maps are flattened back into the BUNDLE_* form
+// (BUNDLE_MIRROR: {ALL: x} becomes BUNDLE_MIRROR__ALL), and lists are joined
+// with ":" as Bundler stores arrays. Scalars keep their text as written, so a
+// token that looks like a number is not reformatted. A file that is not
+// valid YAML is read line by line instead, keeping whatever entries parse.
+//
 // Returns a map of key -> value (both strings).
 func parseBundleConfigYAML(data []byte) map[string]string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return parseBundleConfigLines(data)
+	}
+
+	flat := make(map[string]string)
+	flattenYAML("", &doc, flat)
+
+	// Only keep BUNDLE_ prefixed keys (potential credentials)
+	result := make(map[string]string, len(flat))
+	for k, v := range flat {
+		if strings.HasPrefix(k, "BUNDLE_") {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// flattenYAML writes the scalar values under node into out, joining nested
+// mapping keys to prefix with "__".
+func flattenYAML(prefix string, node *yaml.Node, out map[string]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			flattenYAML(prefix, child, out)
+		}
+
+	case yaml.AliasNode:
+		flattenYAML(prefix, node.Alias, out)
+
+	case yaml.MappingNode:
+		var merged []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				// "<<: *a" or "<<: [*a, *b]"
+				if value.Kind == yaml.SequenceNode {
+					merged = append(merged, value.Content...)
+				} else {
+					merged = append(merged, value)
+				}
+				continue
+			}
+			flattenYAML(joinConfigKey(prefix, key.Value), value, out)
+		}
+
+		// Merged entries never override the mapping's own keys
+		for _, m := range merged {
+			inherited := make(map[string]string)
+			flattenYAML(prefix, m, inherited)
+			for k, v := range inherited {
+				if _, ok := out[k]; !ok {
+					out[k] = v
+				}
+			}
+		}
+
+	case yaml.SequenceNode:
+		if prefix == "" {
+			return
+		}
+		var items []string
+		for _, item := range node.Content {
+			if item.Kind == yaml.AliasNode {
+				item = item.Alias
+			}
+			if item.Kind == yaml.ScalarNode {
+				items = append(items, item.Value)
+			}
+		}
+		if len(items) > 0 {
+			out[prefix] = strings.Join(items, ":")
+		}
+
+	case yaml.ScalarNode:
+		if prefix != "" && node.Tag != "!!null" {
+			out[prefix] = node.Value
+		}
+	}
+}
+
+// joinConfigKey appends a nested YAML key to its parent's BUNDLE_* key.
+func joinConfigKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "__" + key
+}
+
+// parseBundleConfigLines parses "KEY: value" lines one at a time, skipping
+// lines it cannot read. It is the fallback for files that are not valid YAML.
+func parseBundleConfigLines(data []byte) map[string]string {
 	result := make(map[string]string)
 
 	scanner := bufio.NewScanner(strings.NewReader(string(data)))
@@ -149,14 +464,11 @@ func parseBundleConfigYAML(data []byte) map[string]string {
 		}
 
 		// Parse "KEY: value" or "KEY: 'value'" or 'KEY: "value"'
-		idx := strings.Index(line, ":")
-		if idx == -1 {
+		key, value, ok := splitConfigLine(line)
+		if !ok {
 			continue
 		}
 
-		key := strings.TrimSpace(line[:idx])
-		value := strings.TrimSpace(line[idx+1:])
-
 		// Remove surrounding quotes if present
 		value = trimQuotes(value)
 
@@ -174,12 +486,93 @@ func parseBundleConfigYAML(data []byte) map[string]string {
 	return result
 }
 
-// trimQuotes removes surrounding single or double quotes from a string.
-func trimQuotes(s string) string {
-	if len(s) >= 2 {
-		if (s[0] == '"' && s[len(s)-1] == '"') || (s[0] == '\'' && s[len(s)-1] == '\'') {
-			return s[1 : len(s)-1]
+// splitConfigLine splits "KEY: value" at the first ": " (or a trailing ":").
+// Keys for URI-scoped settings contain colons themselves, e.g.
+// "BUNDLE_HTTPS://RUBYGEMS__PKG__GITHUB__COM/ORG/: token".
+func splitConfigLine(line string) (key, value string, ok bool) {
+	idx := strings.Index(line, ": ")
+	if idx == -1 {
+		if !strings.HasSuffix(line, ":") {
+			return "", "", false
 		}
+		idx = len(line) - 1
+	}
+	return strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:]), true
+}
+
+// trimQuotes removes surrounding single or double quotes from a string,
+// undoing YAML quoting inside them: backslash escapes in double quotes and
+// doubled quote characters in single quotes. Everything between the quotes
+// is kept, so values may contain ": " or "#".
+func trimQuotes(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"' && !escapedAt(s, len(s)-1):
+		return unescapeDoubleQuoted(s[1 : len(s)-1])
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
 	}
 	return s
 }
+
+// escapedAt reports whether s[i] is preceded by an odd number of
+// backslashes.
+func escapedAt(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// yamlEscapes maps the single-character YAML double-quote escapes to what
+// they stand for.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unescapeDoubleQuoted expands the escape sequences YAML allows in a
+// double-quoted scalar. Unknown or malformed escapes are kept as written.
+func unescapeDoubleQuoted(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		c := s[i+1]
+		if r, ok := yamlEscapes[c]; ok {
+			b.WriteString(r)
+			i++
+			continue
+		}
+
+		var width int
+		switch c {
+		case 'x':
+			width = 2
+		case 'u':
+			width = 4
+		case 'U':
+			width = 8
+		}
+		if width > 0 && i+2+width <= len(s) {
+			if code, err := strconv.ParseUint(s[i+2:i+2+width], 16, 32); err == nil {
+				b.WriteRune(rune(code))
+				i += 1 + width
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
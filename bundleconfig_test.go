@@ -1,6 +1,8 @@
 package rubygemsclient
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -72,6 +74,129 @@ BUNDLE_PATH: vendor
 				"BUNDLE_PATH": "vendor",
 			},
 		},
+		{
+			name: "URI-scoped keys containing colons",
+			input: `---
+BUNDLE_HTTPS://RUBYGEMS__PKG__GITHUB__COM/ORG/: "any:org_token"
+`,
+			expected: map[string]string{
+				"BUNDLE_HTTPS://RUBYGEMS__PKG__GITHUB__COM/ORG/": "any:org_token",
+			},
+		},
+		{
+			name: "block scalar",
+			input: `---
+BUNDLE_BUILD__NOKOGIRI: >-
+  --use-system-libraries
+  --with-xml2-include=/usr/include/libxml2
+`,
+			expected: map[string]string{
+				"BUNDLE_BUILD__NOKOGIRI": "--use-system-libraries --with-xml2-include=/usr/include/libxml2",
+			},
+		},
+		{
+			name: "nested maps are flattened",
+			input: `---
+BUNDLE_MIRROR:
+  ALL: "https://mirror.example.com"
+  HTTPS://RUBYGEMS__ORG/: "https://local.example.com"
+`,
+			expected: map[string]string{
+				"BUNDLE_MIRROR__ALL":                    "https://mirror.example.com",
+				"BUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/": "https://local.example.com",
+			},
+		},
+		{
+			name: "lists are joined with colons",
+			input: `---
+BUNDLE_WITHOUT:
+  - development
+  - test
+`,
+			expected: map[string]string{
+				"BUNDLE_WITHOUT": "development:test",
+			},
+		},
+		{
+			name: "anchors and merge keys",
+			input: `---
+defaults: &defaults
+  BUNDLE_JOBS: "4"
+  BUNDLE_PATH: vendor/bundle
+<<: *defaults
+BUNDLE_PATH: .gems
+BUNDLE_GEMS__EXAMPLE__COM: &token "any:secret"
+BUNDLE_GEMS__OTHER__COM: *token
+`,
+			expected: map[string]string{
+				"BUNDLE_JOBS":               "4",
+				"BUNDLE_PATH":               ".gems",
+				"BUNDLE_GEMS__EXAMPLE__COM": "any:secret",
+				"BUNDLE_GEMS__OTHER__COM":   "any:secret",
+			},
+		},
+		{
+			name: "scalars keep their written form",
+			input: `---
+BUNDLE_TOKEN: 0x1F
+BUNDLE_FROZEN: yes
+BUNDLE_EMPTY:
+`,
+			expected: map[string]string{
+				"BUNDLE_TOKEN":  "0x1F",
+				"BUNDLE_FROZEN": "yes",
+			},
+		},
+		{
+			name: "quoted values containing colons",
+			input: `---
+BUNDLE_GIT__ORIGIN: "ssh://git@host:22/path"
+BUNDLE_GEMS__EXAMPLE__COM: 'user:pa: ss'
+`,
+			expected: map[string]string{
+				"BUNDLE_GIT__ORIGIN":        "ssh://git@host:22/path",
+				"BUNDLE_GEMS__EXAMPLE__COM": "user:pa: ss",
+			},
+		},
+		{
+			name: "escaped quotes",
+			input: `---
+BUNDLE_GEMS__EXAMPLE__COM: "user:pa\"ss\\word"
+BUNDLE_GEMS__OTHER__COM: 'user:it''s'
+`,
+			expected: map[string]string{
+				"BUNDLE_GEMS__EXAMPLE__COM": `user:pa"ss\word`,
+				"BUNDLE_GEMS__OTHER__COM":   "user:it's",
+			},
+		},
+		{
+			name: "quoted values survive the line fallback",
+			input: `---
+BUNDLE_BROKEN: [unterminated
+BUNDLE_GIT__ORIGIN: "ssh://git@host:22/path"
+BUNDLE_GEMS__EXAMPLE__COM: "user:pa\"ss: \u00e9"
+BUNDLE_GEMS__OTHER__COM: 'user:it''s'
+`,
+			expected: map[string]string{
+				"BUNDLE_BROKEN":             "[unterminated",
+				"BUNDLE_GIT__ORIGIN":        "ssh://git@host:22/path",
+				"BUNDLE_GEMS__EXAMPLE__COM": "user:pa\"ss: \u00e9",
+				"BUNDLE_GEMS__OTHER__COM":   "user:it's",
+			},
+		},
+		{
+			name: "invalid YAML falls back to line parsing",
+			input: `---
+BUNDLE_PATH: vendor/bundle
+BUNDLE_BROKEN: [unterminated
+BUNDLE_JOBS: 4
+`,
+			expected: map[string]string{
+				"BUNDLE_PATH":   "vendor/bundle",
+				"BUNDLE_BROKEN": "[unterminated",
+				"BUNDLE_JOBS":   "4",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -103,6 +228,15 @@ func TestTrimQuotes(t *testing.T) {
 		{`''`, ""},
 		{`"`, `"`},
 		{"", ""},
+		{`"a: b"`, "a: b"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"tab\there\n"`, "tab\there\n"},
+		{`"\x41\u00e9\U0001F48E"`, "A\u00e9\U0001F48E"},
+		{`"unknown \q escape"`, `unknown \q escape`},
+		{`"dangling\"`, `"dangling\"`},
+		{`'it''s'`, "it's"},
+		{`'no \n escapes'`, `no \n escapes`},
 	}
 
 	for _, tt := range tests {
@@ -165,6 +299,9 @@ func TestBundleConfig_CredentialsForHost(t *testing.T) {
 }
 
 func TestLoadBundleConfig_LocalFile(t *testing.T) {
+	ResetConfigCache()
+	defer ResetConfigCache()
+
 	// Create a temporary directory with .bundle/config
 	tmpDir := t.TempDir()
 	bundleDir := filepath.Join(tmpDir, ".bundle")
@@ -199,3 +336,223 @@ BUNDLE_RUBYGEMS__PKG__GITHUB__COM: "any:test_token"
 		t.Errorf("got token %q, want %q", creds.Token, "test_token")
 	}
 }
+
+func TestLoadBundleConfig_AppConfig(t *testing.T) {
+	ResetConfigCache()
+	defer ResetConfigCache()
+
+	tmpDir := t.TempDir()
+	writeConfig := func(dir, token string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "---\nBUNDLE_APPCONFIG__TEST: \"any:" + token + "\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "config"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(filepath.Join(tmpDir, ".bundle"), "default_dir_token")
+	writeConfig(filepath.Join(tmpDir, "ci-bundle"), "app_config_token")
+
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	// Relative BUNDLE_APP_CONFIG replaces .bundle
+	t.Setenv("BUNDLE_APP_CONFIG", "ci-bundle")
+	t.Setenv("BUNDLE_APPCONFIG__TEST", "any:env_token")
+
+	creds := CredentialsFor("appconfig.test")
+	if creds == nil {
+		t.Fatal("expected credentials")
+	}
+	if creds.Token != "app_config_token" {
+		t.Errorf("expected app_config_token (app config > env, .bundle ignored), got %q", creds.Token)
+	}
+}
+
+func TestClassifyConfigKey(t *testing.T) {
+	tests := []struct {
+		key                 string
+		credential, setting bool
+	}{
+		{"BUNDLE_PATH", false, true},
+		{"BUNDLE_JOBS", false, true},
+		{"BUNDLE_GEM__PUSH_KEY", false, true},
+		{"BUNDLE_GEM__TEST", false, true},
+		{"BUNDLE_MIRROR__ALL", false, true},
+		{"BUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/", false, true},
+		{"BUNDLE_RUBYGEMS__PKG__GITHUB__COM", true, false},
+		{"BUNDLE_GEMS__MY___COMPANY__COM", true, false},
+		{"BUNDLE_GEM__EXAMPLE__COM", true, false},
+		{"BUNDLE_HTTPS://GEMS__EXAMPLE__COM/ORG/", true, false},
+		{"BUNDLE_LOCALHOST", true, false},
+		{"BUNDLE_BUILD__NOKOGIRI", true, true},
+		{"BUNDLE_LOCAL__TEST", true, true},
+		{"BUNDLE_BUILD__EXAMPLE__COM", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			credential, setting := classifyConfigKey(tt.key)
+			if credential != tt.credential || setting != tt.setting {
+				t.Errorf("classifyConfigKey(%q) = %v, %v; want %v, %v",
+					tt.key, credential, setting, tt.credential, tt.setting)
+			}
+		})
+	}
+}
+
+func TestParseConfigFile_SeparatesSettings(t *testing.T) {
+	config := parseConfigFile([]byte(`---
+BUNDLE_PATH: "vendor/bundle"
+BUNDLE_GEM__PUSH_KEY: "github"
+BUNDLE_GEMS__EXAMPLE__COM: "user:pass"
+`))
+
+	if creds := config.credentialsForKey("BUNDLE_PATH"); creds != nil {
+		t.Errorf("BUNDLE_PATH parsed as credentials: %+v", creds)
+	}
+	if _, ok := config.Setting("BUNDLE_GEMS__EXAMPLE__COM"); ok {
+		t.Error("credentials exposed as a setting")
+	}
+	if creds := config.CredentialsForHost("gems.example.com"); creds == nil || creds.Password != "pass" {
+		t.Errorf("got credentials %+v, want user:pass", creds)
+	}
+
+	for name, want := range map[string]string{
+		"BUNDLE_PATH":  "vendor/bundle",
+		"path":         "vendor/bundle",
+		"gem.push_key": "github",
+	} {
+		if got, ok := config.Setting(name); !ok || got != want {
+			t.Errorf("Setting(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	if _, ok := config.Setting("jobs"); ok {
+		t.Error("unset setting reported as set")
+	}
+}
+
+func TestSetting_Priority(t *testing.T) {
+	useBundleConfigs(t,
+		"---\nBUNDLE_PATH: \"local/path\"\n",
+		"---\nBUNDLE_PATH: \"global/path\"\nBUNDLE_JOBS: \"8\"\nBUNDLE_RETRY: \"5\"\n"+
+			"BUNDLE_GEMS__EXAMPLE__COM: \"user:pass\"\n")
+	t.Setenv("BUNDLE_JOBS", "2")
+
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"path", "local/path", true},
+		{"jobs", "2", true},
+		{"retry", "5", true},
+		{"BUNDLE_RETRY", "5", true},
+		{"frozen", "", false},
+		{"gems.example.com", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Setting(tt.name)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Setting(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFindBundleConfig(t *testing.T) {
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	mkdir := func(parts ...string) string {
+		t.Helper()
+		dir := filepath.Join(parts...)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	writeConfig := func(dir string) string {
+		t.Helper()
+		path := filepath.Join(mkdir(dir, ".bundle"), "config")
+		if err := os.WriteFile(path, []byte("---\nBUNDLE_PATH: vendor\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	root := t.TempDir()
+	project := mkdir(root, "project")
+	mkdir(project, ".git")
+	want := writeConfig(project)
+	nested := mkdir(project, "app", "models")
+
+	t.Run("same directory", func(t *testing.T) {
+		if got, err := FindBundleConfig(project); err != nil || got != want {
+			t.Errorf("got %q, %v; want %q", got, err, want)
+		}
+	})
+
+	t.Run("nested directory", func(t *testing.T) {
+		if got, err := FindBundleConfig(nested); err != nil || got != want {
+			t.Errorf("got %q, %v; want %q", got, err, want)
+		}
+	})
+
+	t.Run("nearest config wins", func(t *testing.T) {
+		engine := mkdir(project, "engines", "billing")
+		inner := writeConfig(engine)
+		if got, err := FindBundleConfig(engine); err != nil || got != inner {
+			t.Errorf("got %q, %v; want %q", got, err, inner)
+		}
+	})
+
+	t.Run("stops at the git root", func(t *testing.T) {
+		writeConfig(root)
+		repo := mkdir(root, "other")
+		mkdir(repo, ".git")
+		_, err := FindBundleConfig(mkdir(repo, "lib"))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got %v, want fs.ErrNotExist", err)
+		}
+	})
+
+	t.Run("skips the global config", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("BUNDLE_USER_HOME", home)
+		writeConfig(home)
+		_, err := FindBundleConfig(mkdir(home, "src"))
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("got %v, want fs.ErrNotExist", err)
+		}
+	})
+}
+
+func TestLoadBundleConfig_FromSubdirectory(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+	t.Setenv("BUNDLE_APP_CONFIG", "")
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, ".bundle"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nBUNDLE_RUBYGEMS__PKG__GITHUB__COM: \"any:parent_token\"\n"
+	if err := os.WriteFile(filepath.Join(project, ".bundle", "config"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(project, "lib", "tasks")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	creds := GetLocalBundleConfig().CredentialsForHost("rubygems.pkg.github.com")
+	if creds == nil || creds.Token != "parent_token" {
+		t.Errorf("got %+v, want parent_token", creds)
+	}
+}
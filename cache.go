@@ -0,0 +1,223 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response body stored with its validators.
+type CachedResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	StoredAt     time.Time
+
+	// NotFound marks a cached 404; Body holds the server's error message.
+	NotFound bool `json:",omitempty"`
+}
+
+// Cache stores responses for conditional requests. Implementations must be
+// safe for concurrent use; they may be backed by memory, disk, or a shared
+// store such as Redis.
+type Cache interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores an entry under key, replacing any previous one.
+	Set(key string, resp *CachedResponse)
+}
+
+// WithCache enables conditional GET requests. Responses carrying an ETag or
+// Last-Modified header are stored in cache; later requests for the same URL
+// send If-None-Match/If-Modified-Since and reuse the stored body when the
+// server answers 304 Not Modified. Entries are keyed by URL and credentials.
+// 404s are remembered briefly, see WithNegativeCacheTTL. Streaming .gem
+// downloads are not cached.
+func WithCache(cache Cache) ClientOption {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// DefaultNegativeCacheTTL is how long a cached client remembers that a URL
+// answered 404 unless WithNegativeCacheTTL says otherwise.
+const DefaultNegativeCacheTTL = 30 * time.Second
+
+// WithNegativeCacheTTL sets how long WithCache remembers 404 responses, so
+// resolvers probing gems that do not exist do not ask the server again each
+// time; 0 turns negative caching off. A successful response replaces the
+// entry.
+func WithNegativeCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		if ttl <= 0 {
+			c.negativeTTL = -1
+			return
+		}
+		c.negativeTTL = ttl
+	}
+}
+
+// negativeCacheTTL returns how long 404s are cached, or 0 for not at all.
+func (c *Client) negativeCacheTTL() time.Duration {
+	switch {
+	case c.negativeTTL < 0:
+		return 0
+	case c.negativeTTL == 0:
+		return DefaultNegativeCacheTTL
+	default:
+		return c.negativeTTL
+	}
+}
+
+// MemoryCache is an in-memory Cache for the lifetime of a process.
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CachedResponse
+}
+
+// NewMemoryCache creates an empty in-memory cache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]*CachedResponse)}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entry, ok := m.entries[key]
+	return entry, ok
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = resp
+}
+
+// cacheKey returns the key a request's response is cached under: its URL
+// plus a fingerprint of its credentials, so responses fetched with one set of
+// credentials are never served to anonymous requests or to other accounts.
+func (c *Client) cacheKey(req *http.Request) string {
+	key := req.URL.String()
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		key += " auth:" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+// revalidateKey marks a context whose cached GETs must fetch the full body.
+type revalidateKey struct{}
+
+// revalidate returns a copy of ctx under which doCached sends no stored
+// validators and replaces the entry with the fresh response, as when the
+// stored body turned out to be cut short.
+func revalidate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, revalidateKey{}, true)
+}
+
+// doCached sends a GET request through the cache: stored validators are
+// sent along, a 304 is answered from the cache, and fresh responses with
+// validators are stored. A recent 404 is answered from the cache without a
+// request.
+func (c *Client) doCached(req *http.Request) (*http.Response, error) {
+	key := c.cacheKey(req)
+	negativeTTL := c.negativeCacheTTL()
+	cached, hasCached := c.cache.Get(key)
+	if fresh, _ := req.Context().Value(revalidateKey{}).(bool); fresh && hasCached {
+		cached = &CachedResponse{}
+	}
+	if hasCached && cached.NotFound {
+		if negativeTTL > 0 && time.Since(cached.StoredAt) < negativeTTL {
+			return notFoundHTTPResponse(req, cached.Body), nil
+		}
+		// Expired: fetch again, with no validators to send
+		cached = &CachedResponse{}
+	}
+	if hasCached {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		resp.Body.Close()
+		return cachedHTTPResponse(resp, cached.Body), nil
+
+	case resp.StatusCode == http.StatusNotFound && negativeTTL > 0:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.cache.Set(key, &CachedResponse{Body: body, StoredAt: time.Now(), NotFound: true})
+		out := cachedHTTPResponse(resp, body)
+		out.StatusCode, out.Status = resp.StatusCode, resp.Status
+		return out, nil
+
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" && !hasCached {
+			return resp, nil
+		}
+		// Stored even without validators when it replaces an older entry,
+		// such as a 404 from before the gem was published
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		c.cache.Set(key, &CachedResponse{
+			Body:         body,
+			ETag:         etag,
+			LastModified: lastModified,
+			StoredAt:     time.Now(),
+		})
+		return cachedHTTPResponse(resp, body), nil
+	}
+
+	return resp, nil
+}
+
+// notFoundHTTPResponse answers req with a cached 404 serving body.
+func notFoundHTTPResponse(req *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "404 Not Found",
+		StatusCode:    http.StatusNotFound,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Length": {strconv.Itoa(len(body))}},
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		Request:       req,
+	}
+}
+
+// cachedHTTPResponse turns resp into a 200 response serving body.
+func cachedHTTPResponse(resp *http.Response, body []byte) *http.Response {
+	out := *resp
+	out.StatusCode = http.StatusOK
+	out.Status = "200 OK"
+	out.Header = resp.Header.Clone()
+	out.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	out.ContentLength = int64(len(body))
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	return &out
+}
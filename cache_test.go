@@ -0,0 +1,197 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newETagServer serves a version list with an ETag, answering 304 when the
+// client already has it, and counts full responses.
+func newETagServer(t *testing.T, full *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"number": "2.0.0"}, {"number": "1.0.0"}]`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWithCache_ConditionalRequests(t *testing.T) {
+	var full atomic.Int32
+	server := newETagServer(t, &full)
+
+	cache := NewMemoryCache()
+	client := NewClientWithBaseURL(server.URL, WithCache(cache))
+
+	for i := range 3 {
+		versions, err := client.GetGemVersions("test-gem")
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		if want := []string{"2.0.0", "1.0.0"}; !slices.Equal(versions, want) {
+			t.Errorf("request %d: got %v, want %v", i, versions, want)
+		}
+	}
+
+	if got := full.Load(); got != 1 {
+		t.Errorf("expected 1 full response and 304s afterwards, got %d full responses", got)
+	}
+
+	entry, ok := cache.Get(server.URL + "/api/v1/versions/test-gem.json")
+	if !ok || entry.ETag != `"v1"` {
+		t.Errorf("expected cached entry with ETag, got %+v", entry)
+	}
+}
+
+func TestWithCache_LastModified(t *testing.T) {
+	const stamp = "Mon, 01 Jan 2024 00:00:00 GMT"
+	var full atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == stamp {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Last-Modified", stamp)
+		_, _ = w.Write([]byte(`{"name": "test-gem", "version": "1.0.0"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCache(NewMemoryCache()))
+
+	for range 2 {
+		info, err := client.GetGemInfo("test-gem", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info.Version != "1.0.0" {
+			t.Errorf("Version = %q, want 1.0.0", info.Version)
+		}
+	}
+	if got := full.Load(); got != 1 {
+		t.Errorf("expected 1 full response, got %d", got)
+	}
+}
+
+func TestWithCache_SkipsUnvalidatedAndDownloads(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/gems/test-gem-1.0.0.gem" {
+			w.Header().Set("ETag", `"gem"`)
+			_, _ = w.Write([]byte("gem bytes"))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`)) // No validators
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache()
+	client := NewClientWithBaseURL(server.URL, WithCache(cache))
+
+	_, _ = client.GetGemVersions("test-gem")
+	_, _ = client.GetGemVersions("test-gem")
+	if _, err := client.DownloadGem("test-gem", "1.0.0", io.Discard); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected every request to reach the server, got %d", got)
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("expected nothing cached, got %d entries", len(cache.entries))
+	}
+}
+
+func TestWithCache_NegativeCaching(t *testing.T) {
+	var requests atomic.Int32
+	var published atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !published.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("This rubygem could not be found."))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number": "1.0.0"}]`)) // No validators
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache()
+	client := NewClientWithBaseURL(server.URL, WithCache(cache))
+
+	for i := range 3 {
+		_, err := client.GetGemVersions("missing-gem")
+		if !errors.Is(err, ErrGemNotFound) {
+			t.Fatalf("request %d: expected ErrGemNotFound, got %v", i, err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Body != "This rubygem could not be found." {
+			t.Errorf("request %d: expected the server's message, got %v", i, err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("expected the 404 to be answered from the cache, got %d requests", got)
+	}
+
+	// Once the entry expires, a successful fetch replaces it
+	key := server.URL + "/api/v1/versions/missing-gem.json"
+	entry, _ := cache.Get(key)
+	entry.StoredAt = time.Now().Add(-DefaultNegativeCacheTTL)
+	published.Store(true)
+
+	versions, err := client.GetGemVersions("missing-gem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(versions, []string{"1.0.0"}) {
+		t.Errorf("got %v", versions)
+	}
+	if entry, _ := cache.Get(key); entry.NotFound {
+		t.Error("expected the negative entry to be replaced")
+	}
+	if _, err := client.GetGemVersions("missing-gem"); err != nil {
+		t.Errorf("unexpected error after publishing: %v", err)
+	}
+}
+
+func TestWithNegativeCacheTTL(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int32
+	}{
+		{"disabled", 0, 2},
+		{"custom", time.Hour, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			client := NewClientWithBaseURL(server.URL, WithCache(NewMemoryCache()), WithNegativeCacheTTL(tt.ttl))
+			_, _ = client.GetGemVersions("missing-gem")
+			_, _ = client.GetGemVersions("missing-gem")
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("expected %d requests, got %d", tt.want, got)
+			}
+		})
+	}
+}
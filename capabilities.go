@@ -0,0 +1,110 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrNotSupported is returned when the configured server lacks an endpoint.
+var ErrNotSupported = errors.New("not supported by this server")
+
+// Capabilities describes which optional endpoints a gem server implements.
+// rubygems.org supports all of them; private servers often only a subset.
+type Capabilities struct {
+	CompactIndex     bool // /versions and /info/<gem>
+	DependenciesJSON bool // /api/v1/dependencies.json
+	Search           bool // /api/v1/search.json
+	Activity         bool // /api/v1/activity/latest.json
+}
+
+// capabilityProbes maps each capability to the path probed for it, relative to the server root.
+var capabilityProbes = []struct {
+	path string
+	set  func(*Capabilities)
+}{
+	{"/versions", func(c *Capabilities) { c.CompactIndex = true }},
+	{"/api/v1/dependencies.json?gems=rake", func(c *Capabilities) { c.DependenciesJSON = true }},
+	{"/api/v1/search.json?query=rake", func(c *Capabilities) { c.Search = true }},
+	{"/api/v1/activity/latest.json", func(c *Capabilities) { c.Activity = true }},
+}
+
+// DetectCapabilities probes the server for optional endpoints.
+// Probes use HEAD, falling back to GET when the server rejects HEAD.
+// A successful result is cached for the lifetime of the client.
+func (c *Client) DetectCapabilities() (Capabilities, error) {
+	return c.DetectCapabilitiesContext(context.Background())
+}
+
+// DetectCapabilitiesContext is like DetectCapabilities but aborts when ctx is canceled.
+func (c *Client) DetectCapabilitiesContext(ctx context.Context) (Capabilities, error) {
+	c.mu.Lock()
+	cached := c.capabilities
+	c.mu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	root := c.serverRoot()
+	var caps Capabilities
+	for _, probe := range capabilityProbes {
+		ok, err := c.probe(ctx, root+probe.path)
+		if err != nil {
+			return Capabilities{}, fmt.Errorf("failed to probe %s: %w", probe.path, err)
+		}
+		if ok {
+			probe.set(&caps)
+		}
+	}
+
+	c.mu.Lock()
+	c.capabilities = &caps
+	c.mu.Unlock()
+
+	return caps, nil
+}
+
+// probe reports whether url exists. Auth failures count as present: the
+// endpoint is implemented, the caller just can't use it anonymously.
+func (c *Client) probe(ctx context.Context, url string) (bool, error) {
+	status, err := c.probeStatus(ctx, http.MethodHead, url)
+	if err != nil {
+		return false, err
+	}
+	if status == http.StatusMethodNotAllowed {
+		if status, err = c.probeStatus(ctx, http.MethodGet, url); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return true, nil
+	case status >= 400:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+func (c *Client) probeStatus(ctx context.Context, method, url string) (int, error) {
+	req, err := c.newRequest(ctx, method, url)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// serverRoot returns the server URL without the /api/v1 suffix.
+func (c *Client) serverRoot() string {
+	return strings.TrimSuffix(c.baseURL, "/api/v1")
+}
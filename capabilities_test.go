@@ -0,0 +1,52 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/versions":
+			if r.Method != http.MethodHead {
+				t.Errorf("Expected HEAD probe, got %s", r.Method)
+			}
+		case "/api/v1/dependencies.json":
+			// Geminabox-style server that only allows GET
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/api/v1/search.json":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	caps, err := client.DetectCapabilities()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := Capabilities{CompactIndex: true, DependenciesJSON: true, Search: true, Activity: false}
+	if caps != want {
+		t.Errorf("DetectCapabilities() = %+v, want %+v", caps, want)
+	}
+
+	// Second call is served from cache
+	before := requests.Load()
+	if _, err := client.DetectCapabilities(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requests.Load() != before {
+		t.Error("Expected cached capabilities without new requests")
+	}
+}
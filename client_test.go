@@ -1,10 +1,17 @@
 package rubygemsclient
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -29,6 +36,39 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_RubygemsHostEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "unset", value: "", want: "https://rubygems.org/api/v1"},
+		{name: "mirror", value: "https://gems.mirror.test/", want: "https://gems.mirror.test/api/v1"},
+		{name: "not a URL", value: "gems.mirror.test", want: "https://rubygems.org/api/v1"},
+		{name: "unsupported scheme", value: "ftp://gems.mirror.test", want: "https://rubygems.org/api/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RUBYGEMS_HOST", tt.value)
+
+			client := NewClient()
+			if client.baseURL != tt.want {
+				t.Errorf("Expected baseURL %s, got %s", tt.want, client.baseURL)
+			}
+		})
+	}
+
+	t.Run("explicit base URL wins", func(t *testing.T) {
+		t.Setenv("RUBYGEMS_HOST", "https://gems.mirror.test")
+
+		client := NewClientWithBaseURL("https://gems.explicit.test")
+		if client.baseURL != "https://gems.explicit.test/api/v1" {
+			t.Errorf("Expected explicit baseURL, got %s", client.baseURL)
+		}
+	})
+}
+
 func TestGetGemInfo_Success(t *testing.T) {
 	// Mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -104,6 +144,126 @@ func TestGetGemInfo_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetGemInfo_TypedErrors(t *testing.T) {
+	tests := []struct {
+		status       int
+		notFound     bool
+		unauthorized bool
+	}{
+		{http.StatusNotFound, true, false},
+		{http.StatusUnauthorized, false, true},
+		{http.StatusForbidden, false, false},
+		{http.StatusInternalServerError, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("This rubygem could not be found."))
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL)
+
+			_, err := client.GetGemInfo("some-gem", "1.0.0")
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected *APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, apiErr.StatusCode)
+			}
+			if apiErr.GemName != "some-gem" {
+				t.Errorf("Expected gem name some-gem, got %s", apiErr.GemName)
+			}
+			if apiErr.Body != "This rubygem could not be found." {
+				t.Errorf("Unexpected body %q", apiErr.Body)
+			}
+			if got := errors.Is(err, ErrGemNotFound); got != tt.notFound {
+				t.Errorf("errors.Is(err, ErrGemNotFound) = %v, want %v", got, tt.notFound)
+			}
+			if got := errors.Is(err, ErrUnauthorized); got != tt.unauthorized {
+				t.Errorf("errors.Is(err, ErrUnauthorized) = %v, want %v", got, tt.unauthorized)
+			}
+		})
+	}
+}
+
+func TestAPIError_RedactsEchoedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprintf(w, "denied for %s", r.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+
+	for _, creds := range []*Credentials{
+		{Token: "ghp_secret"},
+		{Username: "alice", Password: "hunter2"},
+	} {
+		client := NewClientWithBaseURL(server.URL, WithCredentials(creds))
+
+		_, err := client.GetGemInfo("private-gem", "1.0.0")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *APIError, got %v", err)
+		}
+		if strings.Contains(apiErr.Body, "ghp_secret") || strings.Contains(apiErr.Body, "hunter2") {
+			t.Errorf("APIError body leaked a secret: %q", apiErr.Body)
+		}
+		if apiErr.Body != "denied for Bearer ****" && apiErr.Body != "denied for Basic ****" {
+			t.Errorf("Expected masked Authorization in body, got %q", apiErr.Body)
+		}
+	}
+}
+
+func TestNewClientWithBaseURL_EmbeddedCredentials(t *testing.T) {
+	var gotUser, gotPass, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, _ = r.BasicAuth()
+		gotPath = r.URL.Path
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	withAuth := strings.Replace(server.URL, "http://", "http://alice:s3cret@", 1)
+	client := NewClientWithBaseURL(withAuth + "/private/")
+
+	if strings.Contains(client.baseURL, "s3cret") {
+		t.Errorf("Expected userinfo to be stripped from the base URL, got %s", client.baseURL)
+	}
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotUser != "alice" || gotPass != "s3cret" {
+		t.Errorf("Expected embedded basic auth, got %q/%q", gotUser, gotPass)
+	}
+	if gotPath != "/private/api/v1/gems/test-gem.json" {
+		t.Errorf("Unexpected path %s", gotPath)
+	}
+
+	// Explicit credentials win over the URL's
+	explicit := NewClientWithBaseURL(withAuth, WithCredentials(&Credentials{Token: "override"}))
+	if explicit.credentials.Token != "override" {
+		t.Errorf("Expected WithCredentials to override embedded credentials, got %s", explicit.credentials)
+	}
+}
+
+func TestGetGemVersions_NotFoundIsTyped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	_, err := client.GetGemVersions("missing-gem")
+	if !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("Expected ErrGemNotFound, got %v", err)
+	}
+}
+
 func TestGetGemVersions_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/versions/test-gem.json" {
@@ -172,6 +332,82 @@ func TestGetGemVersions_TooManyVersions(t *testing.T) {
 	}
 }
 
+func TestWithMaxVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions := make([]VersionInfo, 25)
+		for i := range versions {
+			versions[i] = VersionInfo{Number: fmt.Sprintf("1.0.%d", 24-i)}
+		}
+		_ = json.NewEncoder(w).Encode(versions)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want int
+	}{
+		{"default", nil, DefaultMaxVersions},
+		{"custom", []ClientOption{WithMaxVersions(5)}, 5},
+		{"unlimited", []ClientOption{WithMaxVersions(0)}, 25},
+		{"above available", []ClientOption{WithMaxVersions(100)}, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithBaseURL(server.URL, tt.opts...)
+
+			versions, err := client.GetGemVersions("test-gem")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(versions) != tt.want {
+				t.Errorf("Expected %d versions, got %d", tt.want, len(versions))
+			}
+			if versions[0] != "1.0.24" {
+				t.Errorf("Expected newest version first, got %s", versions[0])
+			}
+		})
+	}
+}
+
+func TestGetGemVersionsDetailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"number": "2.0.0.rc1", "created_at": "2024-03-01T10:00:00.000Z", "prerelease": true,
+			 "platform": "ruby", "ruby_version": ">= 3.0", "sha": "abc123"},
+			{"number": "1.0.0", "created_at": "2023-01-01T10:00:00.000Z", "prerelease": false,
+			 "platform": "java", "ruby_version": null, "sha": "def456"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithMaxVersions(1))
+
+	versions, err := client.GetGemVersionsDetailed("test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected untruncated list of 2 versions, got %d", len(versions))
+	}
+
+	want := VersionInfo{
+		Number:      "2.0.0.rc1",
+		CreatedAt:   time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Prerelease:  true,
+		Platform:    "ruby",
+		RubyVersion: ">= 3.0",
+		SHA:         "abc123",
+	}
+	if versions[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, versions[0])
+	}
+	if versions[1].Platform != "java" || versions[1].RubyVersion != "" || versions[1].Prerelease {
+		t.Errorf("Unexpected second version %+v", versions[1])
+	}
+}
+
 func TestClientWithCredentials_Token(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check Authorization header
@@ -276,3 +512,602 @@ func TestGetMultipleGemInfo(t *testing.T) {
 		t.Error("Expected nonexistent gem to fail")
 	}
 }
+
+func TestGetVersionsCreatedBetween(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions := []VersionInfo{
+			{Number: "1.2.0", CreatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{Number: "1.1.0", CreatedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{Number: "1.0.0", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(versions)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	versions, err := client.GetVersionsCreatedBetween("test-gem", from, to)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 versions in window, got %d", len(versions))
+	}
+	if versions[0].Number != "1.2.0" || versions[1].Number != "1.1.0" {
+		t.Errorf("Expected [1.2.0 1.1.0], got [%s %s]", versions[0].Number, versions[1].Number)
+	}
+}
+
+func TestGetGemInfo_Moved(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gems/old-gem.json" {
+			http.Redirect(w, r, "/gems/new-gem.json", http.StatusMovedPermanently)
+			return
+		}
+
+		response := GemInfo{Name: "new-gem", Version: "2.0.0"}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	_, err := client.GetGemInfo("old-gem", "1.0.0")
+	var moved *GemMovedError
+	if !errors.As(err, &moved) {
+		t.Fatalf("Expected GemMovedError, got %v", err)
+	}
+	if moved.OldName != "old-gem" || moved.NewName != "new-gem" {
+		t.Errorf("Expected old-gem -> new-gem, got %s -> %s", moved.OldName, moved.NewName)
+	}
+}
+
+func TestGetMultipleGemInfo_PreservesOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Gem names encode a delay so later requests finish first
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/gems/"), ".json")
+		var delay int
+		_, _ = fmt.Sscanf(name, "gem-%d", &delay)
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+
+		response := GemInfo{Name: name, Version: "1.0.0"}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	delays := []int{40, 5, 30, 0, 20, 10, 35, 15, 25, 1, 45, 3}
+	requests := make([]GemInfoRequest, len(delays))
+	for i, d := range delays {
+		requests[i] = GemInfoRequest{Name: fmt.Sprintf("gem-%d", d), Version: "1.0.0"}
+	}
+
+	results := client.GetMultipleGemInfo(requests)
+
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		if result.Error != nil {
+			t.Fatalf("Unexpected error at index %d: %v", i, result.Error)
+		}
+		if result.Request != requests[i] {
+			t.Errorf("Result %d has request %+v, want %+v", i, result.Request, requests[i])
+		}
+		if result.Info.Name != requests[i].Name {
+			t.Errorf("Result %d has info for %s, want %s", i, result.Info.Name, requests[i].Name)
+		}
+	}
+}
+
+func TestGetGemInfo_InfoAndAuthors(t *testing.T) {
+	bodies := map[string]string{
+		"/gems/described.json": `{"name":"described","version":"1.0.0","info":"A well described gem","authors":"Jane Doe, John Roe"}`,
+		"/gems/bare.json":      `{"name":"bare","version":"1.0.0"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	info, err := client.GetGemInfo("described", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Info != "A well described gem" {
+		t.Errorf("Expected info 'A well described gem', got %q", info.Info)
+	}
+	if info.Authors != "Jane Doe, John Roe" {
+		t.Errorf("Expected authors 'Jane Doe, John Roe', got %q", info.Authors)
+	}
+
+	info, err = client.GetGemInfo("bare", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error for gem without info: %v", err)
+	}
+	if info.Info != "" || info.Authors != "" {
+		t.Errorf("Expected empty info and authors, got %q and %q", info.Info, info.Authors)
+	}
+}
+
+func TestClientAcceptHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{name: "default is JSON", want: MIMEJSON},
+		{name: "explicit Marshal", opts: []ClientOption{WithAccept(MIMEMarshal)}, want: MIMEMarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var mu sync.Mutex
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				got = append(got, r.Header.Get("Accept"))
+				mu.Unlock()
+				if strings.HasPrefix(r.URL.Path, "/api/v1/versions/") {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"name":"test-gem"}`))
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL, tt.opts...)
+			if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := client.GetGemVersions("test-gem"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, accept := range got {
+				if accept != tt.want {
+					t.Errorf("Expected Accept %q, got %q", tt.want, accept)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildClientsForHosts(t *testing.T) {
+	ResetConfigCache()
+	defer ResetConfigCache()
+
+	t.Setenv("BUNDLE_PRIVATE__EXAMPLE__COM", "any:private_token")
+
+	clients, err := BuildClientsForHosts([]string{"rubygems.org", "private.example.com"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(clients) != 2 {
+		t.Fatalf("Expected 2 clients, got %d", len(clients))
+	}
+
+	private := clients["private.example.com"]
+	if private.baseURL != "https://private.example.com/api/v1" {
+		t.Errorf("Unexpected baseURL %s", private.baseURL)
+	}
+	if private.credentials.GetToken() != "private_token" {
+		t.Errorf("Expected private_token, got %q", private.credentials.GetToken())
+	}
+
+	if clients["rubygems.org"].httpClient != private.httpClient {
+		t.Error("Expected clients to share one HTTP client")
+	}
+
+	if _, err := BuildClientsForHosts([]string{"https://bad/host"}); err == nil {
+		t.Error("Expected error for URL passed as host")
+	}
+}
+
+func TestBuildClientsForHosts_HostMatchMode(t *testing.T) {
+	ResetConfigCache()
+	defer ResetConfigCache()
+
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("BUNDLE_CORP__EXAMPLE__COM", "any:corp_token")
+	hosts := []string{"gems.corp.example.com"}
+
+	clients, err := BuildClientsForHosts(hosts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if creds := clients["gems.corp.example.com"].credentials; creds != nil {
+		t.Errorf("Expected no credentials in exact mode, got %v", creds)
+	}
+
+	clients, err = BuildClientsForHosts(hosts, WithHostMatchMode(HostMatchSuffix))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := clients["gems.corp.example.com"].credentials.GetToken(); got != "corp_token" {
+		t.Errorf("Expected the parent domain's corp_token, got %q", got)
+	}
+
+	// Explicit credentials still win
+	clients, err = BuildClientsForHosts(hosts, WithHostMatchMode(HostMatchSuffix), WithCredentials(&Credentials{Token: "explicit"}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := clients["gems.corp.example.com"].credentials.GetToken(); got != "explicit" {
+		t.Errorf("Expected explicit credentials, got %q", got)
+	}
+}
+
+func TestWithRequiredCredentialHosts(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte(`{"name":"test-gem"}`))
+	}))
+	defer server.Close()
+
+	// httptest servers listen on 127.0.0.1
+	client := NewClientWithBaseURL(server.URL, WithRequiredCredentialHosts([]string{"127.0.0.1"}))
+
+	_, err := client.GetGemInfo("test-gem", "1.0.0")
+	var missing *MissingCredentialsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected MissingCredentialsError, got %v", err)
+	}
+	if missing.Host != "127.0.0.1" {
+		t.Errorf("Expected error to name host 127.0.0.1, got %q", missing.Host)
+	}
+	if hits != 0 {
+		t.Errorf("Expected no request to be sent, got %d", hits)
+	}
+
+	// With credentials the request proceeds
+	client = NewClientWithBaseURL(server.URL,
+		WithRequiredCredentialHosts([]string{"127.0.0.1"}),
+		WithCredentials(&Credentials{Token: "secret"}))
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Other hosts are unaffected
+	client = NewClientWithBaseURL(server.URL, WithRequiredCredentialHosts([]string{"gems.private.test"}))
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error for unlisted host: %v", err)
+	}
+}
+
+func TestGetMultipleGemInfo_ResultURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/gems/gem1.json" {
+			http.Redirect(w, r, "/mirror/api/v1/gems/gem1.json", http.StatusFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem1"})
+	}))
+	defer server.Close()
+
+	requests := []GemInfoRequest{{Name: "gem1", Version: "1.0.0"}}
+
+	// Off by default
+	results := NewClientWithBaseURL(server.URL).GetMultipleGemInfo(requests)
+	if results[0].URL != "" {
+		t.Errorf("Expected no URL by default, got %q", results[0].URL)
+	}
+
+	results = NewClientWithBaseURL(server.URL, WithResultURLs()).GetMultipleGemInfo(requests)
+	if results[0].Error != nil {
+		t.Fatalf("Unexpected error: %v", results[0].Error)
+	}
+	if want := server.URL + "/mirror/api/v1/gems/gem1.json"; results[0].URL != want {
+		t.Errorf("Expected URL %q, got %q", want, results[0].URL)
+	}
+}
+
+func TestWithResponseTransformer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "/versions/") {
+			_, _ = w.Write([]byte(`{"data":[{"number":"1.0.0"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"name":"test-gem","info":"wrapped"}}`))
+	}))
+	defer server.Close()
+
+	unwrap := func(body []byte) ([]byte, error) {
+		var envelope struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return nil, err
+		}
+		return envelope.Data, nil
+	}
+	client := NewClientWithBaseURL(server.URL, WithResponseTransformer(unwrap))
+
+	info, err := client.GetGemInfo("test-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Info != "wrapped" {
+		t.Errorf("Expected unwrapped info, got %q", info.Info)
+	}
+
+	versions, err := client.GetGemVersions("test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(versions) != 1 || versions[0] != "1.0.0" {
+		t.Errorf("Expected [1.0.0], got %v", versions)
+	}
+
+	failing := NewClientWithBaseURL(server.URL, WithResponseTransformer(func([]byte) ([]byte, error) {
+		return nil, errors.New("bad envelope")
+	}))
+	if _, err := failing.GetGemInfo("test-gem", "1.0.0"); err == nil || !strings.Contains(err.Error(), "bad envelope") {
+		t.Errorf("Expected transformer error to surface, got %v", err)
+	}
+}
+
+func TestWithMaxRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Two mirrors pointing at each other
+		if r.URL.Path == "/a/api/v1/gems/test-gem.json" {
+			http.Redirect(w, r, "/b/api/v1/gems/test-gem.json", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, "/a/api/v1/gems/test-gem.json", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL+"/a", WithMaxRedirects(3))
+
+	_, err := client.GetGemInfo("test-gem", "1.0.0")
+	var tooMany *TooManyRedirectsError
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("Expected TooManyRedirectsError, got %v", err)
+	}
+	if tooMany.Max != 3 {
+		t.Errorf("Expected max 3, got %d", tooMany.Max)
+	}
+	if len(tooMany.Chain) != 5 {
+		t.Errorf("Expected chain of 5 URLs (original + 4 redirects), got %v", tooMany.Chain)
+	}
+	if !strings.Contains(err.Error(), "/b/api/v1/gems/test-gem.json") {
+		t.Errorf("Expected error to include the redirect chain, got %v", err)
+	}
+}
+
+// countingTransport counts requests passing through to the default transport.
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	custom := &http.Client{Transport: transport, Timeout: 7 * time.Second}
+
+	// WithMaxRedirects before WithHTTPClient still applies
+	client := NewClientWithBaseURL(server.URL, WithMaxRedirects(2), WithHTTPClient(custom))
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := transport.requests.Load(); got != 1 {
+		t.Errorf("Expected the injected transport to serve 1 request, got %d", got)
+	}
+	if client.httpClient.Timeout != 7*time.Second {
+		t.Errorf("Expected the injected client's timeout, got %v", client.httpClient.Timeout)
+	}
+	if client.httpClient.CheckRedirect == nil {
+		t.Error("Expected WithMaxRedirects to apply to the injected client")
+	}
+	if custom.CheckRedirect != nil {
+		t.Error("Expected the caller's http.Client to be left unmodified")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClientWithBaseURL(server.URL, WithTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	if _, err := client.GetGemInfo("slow-gem", "1.0.0"); err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected request to time out quickly, took %v", elapsed)
+	}
+}
+
+func TestWithTimeout_Defaults(t *testing.T) {
+	if got := NewClientWithBaseURL("https://example.com").httpClient.Timeout; got != DefaultTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultTimeout, got)
+	}
+	if got := NewClientWithBaseURL("https://example.com", WithTimeout(0)).httpClient.Timeout; got != 0 {
+		t.Errorf("Expected WithTimeout(0) to disable the timeout, got %v", got)
+	}
+
+	// An injected client keeps its own timeout regardless of option order
+	custom := &http.Client{Timeout: 7 * time.Second}
+	for _, opts := range [][]ClientOption{
+		{WithTimeout(time.Second), WithHTTPClient(custom)},
+		{WithHTTPClient(custom), WithTimeout(time.Second)},
+	} {
+		client := NewClientWithBaseURL("https://example.com", opts...)
+		if client.httpClient.Timeout != 7*time.Second {
+			t.Errorf("Expected injected timeout to win, got %v", client.httpClient.Timeout)
+		}
+	}
+	if custom.Timeout != 7*time.Second {
+		t.Error("Expected the caller's http.Client to be left unmodified")
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var agents []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.Header.Get("User-Agent"))
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, ".gem") {
+			_, _ = w.Write([]byte("gem"))
+			return
+		}
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want string
+	}{
+		{"default", nil, "rubygems-client-go/" + LibraryVersion},
+		{"custom", []ClientOption{WithUserAgent("my-mirror/1.0")}, "my-mirror/1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents = nil
+			client := NewClientWithBaseURL(server.URL, tt.opts...)
+
+			// Info, versions, and downloads all share the request path
+			_, _ = client.GetGemInfo("test-gem", "1.0.0")
+			_, _ = client.GetGemVersions("test-gem")
+			_, _ = client.DownloadGem("test-gem", "1.0.0", io.Discard)
+
+			if len(agents) != 3 {
+				t.Fatalf("Expected 3 requests, got %d", len(agents))
+			}
+			for _, got := range agents {
+				if got != tt.want {
+					t.Errorf("Expected User-Agent %q, got %q", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetGemInfoContext_Canceled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClientWithBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := client.GetGemInfoContext(ctx, "slow-gem", "1.0.0")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Expected cancellation to abort the request promptly")
+	}
+}
+
+func TestGetMultipleGemInfoContext_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	requests := []GemInfoRequest{{Name: "gem1"}, {Name: "gem2"}, {Name: "gem3"}}
+	results := client.GetMultipleGemInfoContext(ctx, requests)
+
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+	for i, result := range results {
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("Result %d: expected context.Canceled, got %v", i, result.Error)
+		}
+		if result.Request != requests[i] {
+			t.Errorf("Result %d: request mismatch %+v", i, result.Request)
+		}
+	}
+}
+
+func TestGetGemVersionsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]VersionInfo{{Number: "1.0.0"}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	versions, err := client.GetGemVersionsContext(context.Background(), "test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(versions) != 1 || versions[0] != "1.0.0" {
+		t.Errorf("Expected [1.0.0], got %v", versions)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetGemVersionsContext(ctx, "test-gem"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultClockSkewThreshold is the skew beyond which ClockSkewExceeded reports true.
+const DefaultClockSkewThreshold = time.Minute
+
+// WithClockSkewThreshold sets the skew tolerated before ClockSkewExceeded
+// reports a problem. The default is DefaultClockSkewThreshold.
+func WithClockSkewThreshold(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.clockSkewThreshold = d
+	}
+}
+
+// ClockSkew returns the offset between the server's Date header and the
+// local clock on the most recent response. Positive means the server is ahead.
+// The boolean is false if no response has carried a valid Date header yet.
+// The Date header has one-second resolution, so small values are noise.
+func (c *Client) ClockSkew() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clockSkew, c.clockSkewSeen
+}
+
+// ClockSkewExceeded reports whether the last observed clock skew, in either
+// direction, is beyond the configured threshold. Large skew makes cache TTLs
+// and conditional requests behave surprisingly.
+func (c *Client) ClockSkewExceeded() bool {
+	skew, ok := c.ClockSkew()
+	if !ok {
+		return false
+	}
+	threshold := c.clockSkewThreshold
+	if threshold <= 0 {
+		threshold = DefaultClockSkewThreshold
+	}
+	return skew > threshold || skew < -threshold
+}
+
+// recordClockSkew stores the skew between resp's Date header and now.
+func (c *Client) recordClockSkew(resp *http.Response, now time.Time) {
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.clockSkew = serverTime.Sub(now).Truncate(time.Second)
+	c.clockSkewSeen = true
+	c.mu.Unlock()
+}
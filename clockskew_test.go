@@ -0,0 +1,69 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRecordClockSkew(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		date     string
+		wantSeen bool
+		wantSkew time.Duration
+		exceeded bool
+	}{
+		{name: "no header", date: "", wantSeen: false},
+		{name: "malformed header", date: "yesterday", wantSeen: false},
+		{name: "in sync", date: now.Format(http.TimeFormat), wantSeen: true},
+		{name: "server ahead", date: now.Add(5 * time.Minute).Format(http.TimeFormat), wantSeen: true, wantSkew: 5 * time.Minute, exceeded: true},
+		{name: "server behind", date: now.Add(-90 * time.Second).Format(http.TimeFormat), wantSeen: true, wantSkew: -90 * time.Second, exceeded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{}
+			resp := &http.Response{Header: http.Header{}}
+			if tt.date != "" {
+				resp.Header.Set("Date", tt.date)
+			}
+
+			client.recordClockSkew(resp, now)
+
+			skew, seen := client.ClockSkew()
+			if seen != tt.wantSeen {
+				t.Fatalf("seen = %v, want %v", seen, tt.wantSeen)
+			}
+			if skew != tt.wantSkew {
+				t.Errorf("skew = %v, want %v", skew, tt.wantSkew)
+			}
+			if got := client.ClockSkewExceeded(); got != tt.exceeded {
+				t.Errorf("ClockSkewExceeded() = %v, want %v", got, tt.exceeded)
+			}
+		})
+	}
+}
+
+func TestWithClockSkewThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+		_, _ = w.Write([]byte(`{"name":"test-gem"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithClockSkewThreshold(time.Hour))
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, seen := client.ClockSkew(); !seen {
+		t.Fatal("Expected clock skew to be recorded")
+	}
+	if client.ClockSkewExceeded() {
+		t.Error("Expected 10m skew to be within 1h threshold")
+	}
+}
@@ -0,0 +1,292 @@
+package rubygemsclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CompactVersions is the parsed compact index /versions file.
+// Ruby equivalent: Bundler::CompactIndexClient::Cache#versions
+//
+// The file is append-only: a gem may appear on several lines, each adding
+// versions (or removing them with a "-" prefix when yanked), and the
+// checksum on the last line for a gem is the current MD5 of its /info file.
+type CompactVersions struct {
+	CreatedAt time.Time
+	Gems      map[string]*CompactGemVersions
+}
+
+// CompactGemVersions holds one gem's versions from the compact index.
+type CompactGemVersions struct {
+	Name         string
+	Versions     []string // In publication order, yanked versions removed
+	InfoChecksum string   // MD5 of the gem's /info file
+}
+
+// ParseCompactVersions parses a full compact index /versions file.
+func ParseCompactVersions(r io.Reader) (*CompactVersions, error) {
+	cv := &CompactVersions{
+		Gems: make(map[string]*CompactGemVersions),
+	}
+	if err := cv.Append(r); err != nil {
+		return nil, err
+	}
+	return cv, nil
+}
+
+// Append applies additional /versions content, such as the tail fetched with
+// a Range request, on top of what has already been parsed.
+// A header (created_at and "---") is accepted but not required.
+func (cv *CompactVersions) Append(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line == "---" {
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(line, "created_at:"); ok {
+			createdAt, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("invalid created_at on line %d: %w", lineNum, err)
+			}
+			cv.CreatedAt = createdAt
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return fmt.Errorf("malformed versions line %d: %q", lineNum, line)
+		}
+		cv.apply(fields[0], strings.Split(fields[1], ","), fields[2])
+	}
+
+	return scanner.Err()
+}
+
+// apply merges one versions line into the gem's entry.
+func (cv *CompactVersions) apply(name string, versions []string, checksum string) {
+	gem, ok := cv.Gems[name]
+	if !ok {
+		gem = &CompactGemVersions{Name: name}
+		cv.Gems[name] = gem
+	}
+
+	for _, v := range versions {
+		if yanked, ok := strings.CutPrefix(v, "-"); ok {
+			gem.Versions = removeVersion(gem.Versions, yanked)
+			continue
+		}
+		gem.Versions = append(gem.Versions, v)
+	}
+	gem.InfoChecksum = checksum
+}
+
+// removeVersion removes the last occurrence of version from versions.
+func removeVersion(versions []string, version string) []string {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i] == version {
+			return append(versions[:i], versions[i+1:]...)
+		}
+	}
+	return versions
+}
+
+// CompactInfoVersion is one line of a compact index /info/<gem> file:
+// a single version/platform with its own runtime dependencies.
+// Ruby equivalent: Bundler::CompactIndexClient::Parser#info
+type CompactInfoVersion struct {
+	Version          string
+	Platform         string // Empty for the default "ruby" platform
+	Dependencies     []Dependency
+	Checksum         string // SHA-256 of the .gem file
+	RequiredRuby     string // e.g. ">= 2.7, < 4", empty if unconstrained
+	RequiredRubyGems string
+}
+
+// ParseCompactInfo parses a compact index /info/<gem> file. Each line is
+//
+//	<version>[-<platform>] <dep>:<req>&<req>,<dep>:<req>|checksum:<sha>,ruby:<req>,rubygems:<req>
+//
+// Multiple requirements joined by "&" are returned comma-separated, matching
+// the JSON API's requirements strings.
+func ParseCompactInfo(r io.Reader) ([]CompactInfoVersion, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var versions []CompactInfoVersion
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line == "---" {
+			continue
+		}
+
+		entry, err := parseCompactInfoLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("malformed info line %d: %w", lineNum, err)
+		}
+		versions = append(versions, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// parseCompactInfoLine parses a single /info line.
+func parseCompactInfoLine(line string) (CompactInfoVersion, error) {
+	head, meta, _ := strings.Cut(line, "|")
+	versionPart, depsPart, _ := strings.Cut(strings.TrimSpace(head), " ")
+	if versionPart == "" {
+		return CompactInfoVersion{}, fmt.Errorf("missing version in %q", line)
+	}
+
+	// RubyGems versions never contain "-", so the first one starts the platform
+	var entry CompactInfoVersion
+	entry.Version, entry.Platform, _ = strings.Cut(versionPart, "-")
+
+	for _, dep := range splitNonEmpty(depsPart, ",") {
+		name, req, ok := strings.Cut(dep, ":")
+		if !ok || name == "" {
+			return CompactInfoVersion{}, fmt.Errorf("invalid dependency %q", dep)
+		}
+		entry.Dependencies = append(entry.Dependencies, Dependency{
+			Name:         name,
+			Requirements: joinRequirements(req),
+		})
+	}
+
+	for _, field := range splitNonEmpty(meta, ",") {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return CompactInfoVersion{}, fmt.Errorf("invalid metadata %q", field)
+		}
+		switch key {
+		case "checksum":
+			entry.Checksum = value
+		case "ruby":
+			entry.RequiredRuby = joinRequirements(value)
+		case "rubygems":
+			entry.RequiredRubyGems = joinRequirements(value)
+		}
+	}
+
+	return entry, nil
+}
+
+// splitNonEmpty splits s on sep, dropping blank parts.
+func splitNonEmpty(s, sep string) []string {
+	var parts []string
+	for part := range strings.SplitSeq(s, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// joinRequirements converts "&"-joined requirements to the ", " form.
+func joinRequirements(req string) string {
+	return strings.Join(splitNonEmpty(req, "&"), ", ")
+}
+
+// CompactIndexClient reads per-version data from a server's compact index.
+// Unlike GetGemInfo, which reports the latest version's dependencies, it
+// returns the dependencies each version actually declared.
+type CompactIndexClient struct {
+	client *Client
+}
+
+// NewCompactIndexClient creates a compact index client that shares the given
+// client's server, credentials, and transport settings.
+func NewCompactIndexClient(client *Client) *CompactIndexClient {
+	return &CompactIndexClient{client: client}
+}
+
+// GetInfo fetches and parses /info/<gem>.
+func (ci *CompactIndexClient) GetInfo(name string) ([]CompactInfoVersion, error) {
+	return ci.GetInfoContext(context.Background(), name)
+}
+
+// GetInfoContext is like GetInfo but aborts when ctx is canceled.
+func (ci *CompactIndexClient) GetInfoContext(ctx context.Context, name string) ([]CompactInfoVersion, error) {
+	c := ci.client
+	req, err := c.newRequest(withGemName(ctx, name), "GET", c.serverRoot()+"/info/"+url.PathEscape(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch compact index info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, name)
+	}
+
+	return ParseCompactInfo(resp.Body)
+}
+
+// GetGemInfoForVersion returns gem info whose runtime dependencies are the
+// ones declared by that exact version. When a version was published for
+// several platforms, the build for the client's WithPlatform target wins,
+// then the default ruby build. Returns ErrNoMatchingVersion if the version
+// has no build installable on the target.
+func (ci *CompactIndexClient) GetGemInfoForVersion(name, version string) (*GemInfo, error) {
+	return ci.GetGemInfoForVersionContext(context.Background(), name, version)
+}
+
+// GetGemInfoForVersionContext is like GetGemInfoForVersion but aborts when ctx is canceled.
+func (ci *CompactIndexClient) GetGemInfoForVersionContext(ctx context.Context, name, version string) (*GemInfo, error) {
+	versions, err := ci.GetInfoContext(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	target := ci.client.platform
+	var match *CompactInfoVersion
+	bestRank := 0
+	for i := range versions {
+		if versions[i].Version != version {
+			continue
+		}
+		if rank := platformRank(target, versions[i].Platform); rank > bestRank {
+			match, bestRank = &versions[i], rank
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("%s %s: %w", name, version, ErrNoMatchingVersion)
+	}
+
+	platform := match.Platform
+	if platform == "" {
+		platform = string(PlatformRuby)
+	}
+
+	return &GemInfo{
+		Name:     name,
+		Version:  match.Version,
+		Platform: platform,
+		Dependencies: DependencyCategories{
+			Runtime: match.Dependencies,
+		},
+	}, nil
+}
@@ -0,0 +1,195 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const versionsFixture = `created_at: 2024-04-01T00:00:05Z
+---
+rack 1.0.0,1.1.0 aaaa1111
+nokogiri 1.15.0,1.15.0-x86_64-linux,1.15.0-java bbbb2222
+rack 1.2.0 cccc3333
+nokogiri -1.15.0-java dddd4444
+`
+
+func TestParseCompactVersions(t *testing.T) {
+	cv, err := ParseCompactVersions(strings.NewReader(versionsFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantCreated := time.Date(2024, 4, 1, 0, 0, 5, 0, time.UTC)
+	if !cv.CreatedAt.Equal(wantCreated) {
+		t.Errorf("CreatedAt = %v, want %v", cv.CreatedAt, wantCreated)
+	}
+
+	rack := cv.Gems["rack"]
+	if rack == nil {
+		t.Fatal("expected rack entry")
+	}
+	if want := []string{"1.0.0", "1.1.0", "1.2.0"}; !slices.Equal(rack.Versions, want) {
+		t.Errorf("rack versions = %v, want %v", rack.Versions, want)
+	}
+	if rack.InfoChecksum != "cccc3333" {
+		t.Errorf("rack checksum = %q, want latest line's checksum", rack.InfoChecksum)
+	}
+
+	nokogiri := cv.Gems["nokogiri"]
+	if want := []string{"1.15.0", "1.15.0-x86_64-linux"}; !slices.Equal(nokogiri.Versions, want) {
+		t.Errorf("nokogiri versions = %v, want %v (yanked java removed)", nokogiri.Versions, want)
+	}
+}
+
+func TestCompactVersions_Append(t *testing.T) {
+	cv, err := ParseCompactVersions(strings.NewReader(versionsFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Incremental tail has no header
+	if err := cv.Append(strings.NewReader("rack 1.3.0 eeee5555\nrails 7.0.0 ffff6666\n")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0"}; !slices.Equal(cv.Gems["rack"].Versions, want) {
+		t.Errorf("rack versions = %v, want %v", cv.Gems["rack"].Versions, want)
+	}
+	if cv.Gems["rack"].InfoChecksum != "eeee5555" {
+		t.Errorf("rack checksum = %q, want eeee5555", cv.Gems["rack"].InfoChecksum)
+	}
+	if cv.Gems["rails"] == nil {
+		t.Error("expected rails entry from appended content")
+	}
+}
+
+func TestParseCompactVersions_Malformed(t *testing.T) {
+	_, err := ParseCompactVersions(strings.NewReader("---\nrack 1.0.0\n"))
+	if err == nil {
+		t.Fatal("expected error for line missing checksum")
+	}
+}
+
+const infoFixture = `---
+1.14.0 mini_portile2:~> 2.8.0,racc:~> 1.4|checksum:1111,ruby:>= 2.7&< 3.3.dev,rubygems:>= 3.1
+1.15.0 mini_portile2:~> 2.8.2,racc:~> 1.4|checksum:2222,ruby:>= 3.0&< 3.4.dev
+1.15.0-x86_64-linux racc:~> 1.4|checksum:3333,ruby:>= 3.0&< 3.4.dev
+1.15.0-java racc:~> 1.4|checksum:4444
+1.16.0-arm64-darwin racc:~> 1.4|checksum:5555
+1.16.0 |checksum:6666
+`
+
+func TestParseCompactInfo(t *testing.T) {
+	versions, err := ParseCompactInfo(strings.NewReader(infoFixture))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 6 {
+		t.Fatalf("got %d versions, want 6", len(versions))
+	}
+
+	first := versions[0]
+	if first.Version != "1.14.0" || first.Platform != "" {
+		t.Errorf("first = %s/%s, want 1.14.0 on ruby", first.Version, first.Platform)
+	}
+	wantDeps := []Dependency{
+		{Name: "mini_portile2", Requirements: "~> 2.8.0"},
+		{Name: "racc", Requirements: "~> 1.4"},
+	}
+	if !slices.Equal(first.Dependencies, wantDeps) {
+		t.Errorf("deps = %v, want %v", first.Dependencies, wantDeps)
+	}
+	if first.Checksum != "1111" {
+		t.Errorf("checksum = %q, want 1111", first.Checksum)
+	}
+	if first.RequiredRuby != ">= 2.7, < 3.3.dev" {
+		t.Errorf("ruby = %q, want multi-requirement joined with commas", first.RequiredRuby)
+	}
+	if first.RequiredRubyGems != ">= 3.1" {
+		t.Errorf("rubygems = %q, want >= 3.1", first.RequiredRubyGems)
+	}
+
+	linux := versions[2]
+	if linux.Version != "1.15.0" || linux.Platform != "x86_64-linux" {
+		t.Errorf("platform entry = %s/%s, want 1.15.0/x86_64-linux", linux.Version, linux.Platform)
+	}
+	if linux.RequiredRubyGems != "" {
+		t.Errorf("rubygems = %q, want empty when not given", linux.RequiredRubyGems)
+	}
+
+	noDeps := versions[5]
+	if len(noDeps.Dependencies) != 0 || noDeps.Checksum != "6666" {
+		t.Errorf("dependency-free entry parsed as %+v", noDeps)
+	}
+}
+
+func TestParseCompactInfo_Malformed(t *testing.T) {
+	if _, err := ParseCompactInfo(strings.NewReader("1.0.0 rack|checksum:1\n")); err == nil {
+		t.Error("expected error for dependency without requirement")
+	}
+}
+
+func newInfoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info/nokogiri" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(infoFixture))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCompactIndexClient_GetGemInfoForVersion(t *testing.T) {
+	server := newInfoServer(t)
+	ci := NewCompactIndexClient(NewClientWithBaseURL(server.URL))
+
+	info, err := ci.GetGemInfoForVersion("nokogiri", "1.14.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Name != "nokogiri" || info.Version != "1.14.0" {
+		t.Errorf("got %s %s, want nokogiri 1.14.0", info.Name, info.Version)
+	}
+	if want := "~> 2.8.0"; info.Dependencies.Runtime[0].Requirements != want {
+		t.Errorf("mini_portile2 requirement = %q, want the 1.14.0 value %q",
+			info.Dependencies.Runtime[0].Requirements, want)
+	}
+
+	// 1.15.0 has platform builds; the ruby platform entry wins
+	info, err = ci.GetGemInfoForVersion("nokogiri", "1.15.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(info.Dependencies.Runtime) != 2 {
+		t.Errorf("expected ruby platform deps, got %v", info.Dependencies.Runtime)
+	}
+
+	// 1.16.0 lists a platform build first; still prefer ruby
+	info, err = ci.GetGemInfoForVersion("nokogiri", "1.16.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(info.Dependencies.Runtime) != 0 {
+		t.Errorf("expected ruby platform (no deps), got %v", info.Dependencies.Runtime)
+	}
+}
+
+func TestCompactIndexClient_Errors(t *testing.T) {
+	server := newInfoServer(t)
+	ci := NewCompactIndexClient(NewClientWithBaseURL(server.URL))
+
+	if _, err := ci.GetGemInfoForVersion("nokogiri", "9.9.9"); !errors.Is(err, ErrNoMatchingVersion) {
+		t.Errorf("expected ErrNoMatchingVersion, got %v", err)
+	}
+	if _, err := ci.GetGemInfoForVersion("missing", "1.0.0"); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package rubygemsclient
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithCompression controls whether requests ask for gzip-encoded responses
+// (the default). Compressed bodies are decoded before anything else reads
+// them, including response transformers. Disabling it requests identity
+// encoding, which is mostly useful when debugging with a proxy.
+func WithCompression(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.disableCompression = !enabled
+	}
+}
+
+// acceptEncoding returns the Accept-Encoding header value for requests.
+// Setting it explicitly turns off net/http's own transparent gzip, so
+// decompressResponse takes over.
+func (c *Client) acceptEncoding() string {
+	if c.disableCompression {
+		return "identity"
+	}
+	return "gzip"
+}
+
+// CompressionStats totals the gzip-encoded responses a client has read, to
+// show how much bandwidth compression saves, or that a server sends gzip
+// that does not shrink.
+type CompressionStats struct {
+	Responses    int64 // gzip-encoded responses
+	Compressed   int64 // Bytes received
+	Decompressed int64 // Bytes after decoding
+}
+
+// CompressionStats returns the totals for gzip-encoded responses read so far.
+// A response is counted once its body is closed.
+func (c *Client) CompressionStats() CompressionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.compression
+}
+
+// StatsAndReset returns the totals like CompressionStats and zeroes them in
+// the same step, so periodic reporting sees each response exactly once even
+// while requests are in flight.
+func (c *Client) StatsAndReset() CompressionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.compression
+	c.compression = CompressionStats{}
+	return stats
+}
+
+// recordCompression adds one closed gzip body to the stats.
+func (c *Client) recordCompression(compressed, decompressed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compression.Responses++
+	c.compression.Compressed += compressed
+	c.compression.Decompressed += decompressed
+}
+
+// decompressResponse replaces a gzip-encoded body with its decoded stream
+// and drops the encoding headers, as net/http does for transparent gzip.
+func (c *Client) decompressResponse(resp *http.Response) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body, client: c}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decodes a gzip stream lazily, so empty bodies (HEAD responses,
+// errors) are never parsed as gzip unless read. It counts the bytes on both
+// sides for CompressionStats.
+type gzipBody struct {
+	body   io.ReadCloser
+	zr     *gzip.Reader
+	err    error
+	client *Client
+
+	compressed   int64
+	decompressed int64
+	closed       bool
+}
+
+func (g *gzipBody) Read(p []byte) (int, error) {
+	if g.zr == nil && g.err == nil {
+		g.zr, g.err = gzip.NewReader(countingReader{g.body, &g.compressed})
+	}
+	if g.err != nil {
+		return 0, g.err
+	}
+	n, err := g.zr.Read(p)
+	g.decompressed += int64(n)
+	return n, err
+}
+
+func (g *gzipBody) Close() error {
+	if !g.closed && g.zr != nil && g.client != nil {
+		g.closed = true
+		g.client.recordCompression(g.compressed, g.decompressed)
+	}
+	return g.body.Close()
+}
+
+// countingReader adds the bytes read from r to *n.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
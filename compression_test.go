@@ -0,0 +1,164 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newGzipServer serves gem info gzipped when the client asks for it and
+// records the Accept-Encoding header it saw.
+func newGzipServer(t *testing.T, seen *string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept-Encoding")
+		mu.Lock()
+		*seen = accept
+		mu.Unlock()
+		body, _ := json.Marshal(GemInfo{Name: "test-gem", Version: "1.0.0"})
+		if !strings.Contains(accept, "gzip") {
+			_, _ = w.Write(body)
+			return
+		}
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		_ = zw.Close()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCompression_DecodesGzip(t *testing.T) {
+	var seen string
+	server := newGzipServer(t, &seen)
+
+	var transformed []byte
+	client := NewClientWithBaseURL(server.URL, WithResponseTransformer(func(b []byte) ([]byte, error) {
+		transformed = b
+		return b, nil
+	}))
+
+	info, err := client.GetGemInfo("test-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "gzip" {
+		t.Errorf("Accept-Encoding = %q, want gzip", seen)
+	}
+	if info.Name != "test-gem" {
+		t.Errorf("Name = %q, want test-gem", info.Name)
+	}
+	if !json.Valid(transformed) {
+		t.Errorf("transformer saw undecoded body %q", transformed)
+	}
+}
+
+func TestCompression_Disabled(t *testing.T) {
+	var seen string
+	server := newGzipServer(t, &seen)
+
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "identity" {
+		t.Errorf("Accept-Encoding = %q, want identity", seen)
+	}
+}
+
+func TestCompression_CorruptBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write([]byte("not gzip"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+		t.Error("expected error for corrupt gzip body")
+	}
+}
+
+func TestCompressionStats(t *testing.T) {
+	var seen string
+	server := newGzipServer(t, &seen)
+	body, _ := json.Marshal(GemInfo{Name: "test-gem", Version: "1.0.0"})
+
+	client := NewClientWithBaseURL(server.URL)
+	for range 2 {
+		if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	stats := client.CompressionStats()
+	if stats.Responses != 2 || stats.Decompressed != int64(2*len(body)) || stats.Compressed == 0 {
+		t.Errorf("unexpected stats %+v for two %d-byte bodies", stats, len(body))
+	}
+
+	identity := NewClientWithBaseURL(server.URL, WithCompression(false))
+	if _, err := identity.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats := identity.CompressionStats(); stats != (CompressionStats{}) {
+		t.Errorf("expected no stats without compression, got %+v", stats)
+	}
+}
+
+func TestStatsAndReset(t *testing.T) {
+	var seen string
+	server := newGzipServer(t, &seen)
+	body, _ := json.Marshal(GemInfo{Name: "test-gem", Version: "1.0.0"})
+	client := NewClientWithBaseURL(server.URL)
+
+	const workers, perWorker = 4, 10
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for range perWorker {
+				if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+
+	// Reporting windows taken while requests run must add up to the total
+	var total CompressionStats
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		window := client.StatsAndReset()
+		total.Responses += window.Responses
+		total.Decompressed += window.Decompressed
+	}
+
+	if total.Responses != workers*perWorker || total.Decompressed != int64(workers*perWorker*len(body)) {
+		t.Errorf("windows add up to %+v, want %d responses of %d bytes", total, workers*perWorker, len(body))
+	}
+	if stats := client.CompressionStats(); stats != (CompressionStats{}) {
+		t.Errorf("expected zeroed stats after the last reset, got %+v", stats)
+	}
+}
@@ -0,0 +1,37 @@
+package rubygemsclient
+
+import "context"
+
+// DefaultConcurrency is how many requests a client's batch methods run at
+// once unless WithConcurrency says otherwise.
+const DefaultConcurrency = 10
+
+// WithConcurrency limits how many requests the batch methods
+// (GetMultipleGemInfo, GetDependenciesBulk, DownloadGems) have in flight at
+// once. The limit is shared by every batch call on the client, so concurrent
+// or repeated calls cannot exceed it together. Zero or a negative n means
+// DefaultConcurrency.
+func WithConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		c.concurrency = n
+	}
+}
+
+// acquireSlot waits for one of the client's batch request slots and returns
+// the function that frees it, or ctx's error if ctx ends first.
+func (c *Client) acquireSlot(ctx context.Context) (release func(), err error) {
+	c.slotsOnce.Do(func() {
+		n := c.concurrency
+		if n <= 0 {
+			n = DefaultConcurrency
+		}
+		c.slots = make(chan struct{}, n)
+	})
+
+	select {
+	case c.slots <- struct{}{}:
+		return func() { <-c.slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
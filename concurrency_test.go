@@ -0,0 +1,66 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithConcurrency_SharedAcrossCalls(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem", Version: "1.0.0"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithConcurrency(3))
+
+	requests := make([]GemInfoRequest, 6)
+	for i := range requests {
+		requests[i] = GemInfoRequest{Name: fmt.Sprintf("gem%d", i)}
+	}
+
+	// Four batches at once must still share the client's three slots
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for _, result := range client.GetMultipleGemInfo(requests) {
+				if result.Error != nil {
+					t.Errorf("unexpected error: %v", result.Error)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 3 {
+		t.Errorf("peak in-flight requests = %d, want at most 3", got)
+	}
+}
+
+func TestWithConcurrency_Default(t *testing.T) {
+	client := NewClient()
+	release, err := client.acquireSlot(t.Context())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	if got := cap(client.slots); got != DefaultConcurrency {
+		t.Errorf("slots = %d, want %d", got, DefaultConcurrency)
+	}
+}
@@ -0,0 +1,46 @@
+package rubygemsclient
+
+import "sync/atomic"
+
+// defaultClient backs the package-level convenience functions.
+var defaultClient atomic.Pointer[Client]
+
+// DefaultClient returns the client used by the package-level functions,
+// creating a rubygems.org client on first use.
+func DefaultClient() *Client {
+	if c := defaultClient.Load(); c != nil {
+		return c
+	}
+	// Loading again after the swap could see a concurrent SetDefaultClient(nil)
+	fresh := NewClient()
+	for {
+		if defaultClient.CompareAndSwap(nil, fresh) {
+			return fresh
+		}
+		if c := defaultClient.Load(); c != nil {
+			return c
+		}
+	}
+}
+
+// SetDefaultClient replaces the client used by the package-level functions.
+// Passing nil restores a fresh rubygems.org client on next use.
+// It is safe to call concurrently with requests.
+func SetDefaultClient(c *Client) {
+	defaultClient.Store(c)
+}
+
+// GetGemInfo fetches gem metadata using the default client.
+func GetGemInfo(name, version string) (*GemInfo, error) {
+	return DefaultClient().GetGemInfo(name, version)
+}
+
+// GetGemVersions fetches versions for a gem using the default client.
+func GetGemVersions(name string) ([]string, error) {
+	return DefaultClient().GetGemVersions(name)
+}
+
+// GetMultipleGemInfo fetches metadata for several gems using the default client.
+func GetMultipleGemInfo(requests []GemInfoRequest) []GemInfoResult {
+	return DefaultClient().GetMultipleGemInfo(requests)
+}
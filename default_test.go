@@ -0,0 +1,74 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestDefaultClient(t *testing.T) {
+	defer SetDefaultClient(nil)
+
+	SetDefaultClient(nil)
+	c := DefaultClient()
+	if c == nil || c.baseURL != "https://rubygems.org/api/v1" {
+		t.Fatalf("Expected lazily created rubygems.org client, got %+v", c)
+	}
+	if DefaultClient() != c {
+		t.Error("Expected DefaultClient to return the same client on each call")
+	}
+}
+
+func TestPackageLevelGetGemInfo(t *testing.T) {
+	defer SetDefaultClient(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	SetDefaultClient(NewClientWithBaseURL(server.URL))
+
+	info, err := GetGemInfo("test-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "test-gem" {
+		t.Errorf("Expected test-gem, got %s", info.Name)
+	}
+}
+
+func TestSetDefaultClient_Concurrent(t *testing.T) {
+	defer SetDefaultClient(nil)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			SetDefaultClient(NewClient())
+		})
+		wg.Go(func() {
+			_ = DefaultClient()
+		})
+	}
+	wg.Wait()
+}
+
+func TestDefaultClient_NeverNilWhileReset(t *testing.T) {
+	defer SetDefaultClient(nil)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			SetDefaultClient(nil)
+		})
+		wg.Go(func() {
+			if DefaultClient() == nil {
+				t.Error("DefaultClient returned nil")
+			}
+		})
+	}
+	wg.Wait()
+}
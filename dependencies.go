@@ -0,0 +1,192 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// dependencyChunkSize is how many gems GetDependenciesBulk asks for per
+// request, keeping URLs well under common length limits.
+const dependencyChunkSize = 50
+
+// dependencyEntry is one version from the /dependencies.json endpoint.
+type dependencyEntry struct {
+	Name         string     `json:"name"`
+	Number       string     `json:"number"`
+	Platform     string     `json:"platform"`
+	Dependencies [][]string `json:"dependencies"` // [name, requirements] pairs
+}
+
+// GetDependenciesBulk returns the runtime dependencies of the latest version
+// of each named gem, like GetGemInfo reports them, using the bulk
+// /dependencies.json?gems=a,b,c endpoint: a 200-gem lockfile takes four
+// requests rather than 200. Prereleases are only used for gems that have no
+// stable version, and platform builds follow WithPlatform.
+//
+// Servers that have dropped the bulk endpoint (rubygems.org among them) answer
+// 404; for those the gems are read from the compact index instead, one
+// request per gem. Gems the server does not know are absent from the map.
+// Chunks are fetched in parallel within the WithConcurrency limit.
+// If some requests fail, the map holds everything that was fetched and the
+// error is a *DependenciesError naming each missing gem and why.
+func (c *Client) GetDependenciesBulk(names []string) (map[string][]Dependency, error) {
+	return c.GetDependenciesBulkContext(context.Background(), names)
+}
+
+// GetDependenciesBulkContext is like GetDependenciesBulk but aborts when ctx is canceled.
+func (c *Client) GetDependenciesBulkContext(ctx context.Context, names []string) (map[string][]Dependency, error) {
+	chunks := chunkNames(names, dependencyChunkSize)
+
+	var (
+		mu      sync.Mutex
+		entries []dependencyEntry
+		failed  DependenciesError
+		wg      sync.WaitGroup
+	)
+
+	for _, chunk := range chunks {
+		wg.Go(func() {
+			release, err := c.acquireSlot(ctx)
+			if err != nil {
+				mu.Lock()
+				failed.fail(chunk, err)
+				mu.Unlock()
+				return
+			}
+			defer release()
+
+			got, chunkFailed := c.fetchDependencyChunk(ctx, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			entries = append(entries, got...)
+			failed.merge(chunkFailed)
+		})
+	}
+	wg.Wait()
+
+	return c.latestDependencies(entries), failed.orNil()
+}
+
+// chunkNames removes blank and repeated names and splits the rest into
+// groups of at most size, keeping their order.
+func chunkNames(names []string, size int) [][]string {
+	seen := make(map[string]bool, len(names))
+	var chunks [][]string
+	var current []string
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		current = append(current, name)
+		if len(current) == size {
+			chunks = append(chunks, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// fetchDependencyChunk fetches one group of gems from the bulk endpoint,
+// falling back to the compact index if the server does not have it. Gems
+// that could not be fetched are reported in the returned error, if any.
+func (c *Client) fetchDependencyChunk(ctx context.Context, names []string) ([]dependencyEntry, *DependenciesError) {
+	var entries []dependencyEntry
+	query := url.Values{"gems": {strings.Join(names, ",")}}
+	endpoint := c.baseURL + "/dependencies.json?" + query.Encode()
+	err := c.getJSON(ctx, endpoint, strings.Join(names, ", "), "dependencies", &entries)
+	if errors.Is(err, ErrGemNotFound) {
+		return c.compactDependencies(ctx, names)
+	}
+	if err != nil {
+		var failed DependenciesError
+		failed.fail(names, err)
+		return entries, &failed
+	}
+	return entries, nil
+}
+
+// compactDependencies reads each gem's /info file from the compact index.
+// Gems the index does not have are skipped.
+func (c *Client) compactDependencies(ctx context.Context, names []string) ([]dependencyEntry, *DependenciesError) {
+	ci := NewCompactIndexClient(c)
+	var entries []dependencyEntry
+	var failed DependenciesError
+	for i, name := range names {
+		versions, err := ci.GetInfoContext(ctx, name)
+		if errors.Is(err, ErrGemNotFound) {
+			continue
+		}
+		if err != nil {
+			failed.fail([]string{name}, fmt.Errorf("dependencies for %s: %w", name, err))
+			if ctx.Err() != nil {
+				// The rest of the chunk is never asked for
+				failed.fail(names[i+1:], ctx.Err())
+				break
+			}
+			continue
+		}
+		for _, v := range versions {
+			entry := dependencyEntry{Name: name, Number: v.Version, Platform: v.Platform}
+			for _, dep := range v.Dependencies {
+				entry.Dependencies = append(entry.Dependencies, []string{dep.Name, dep.Requirements})
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if len(failed.Gems) == 0 {
+		return entries, nil
+	}
+	return entries, &failed
+}
+
+// latestDependencies picks each gem's newest installable version, preferring
+// stable releases and then the best platform build, and returns its
+// dependencies.
+func (c *Client) latestDependencies(entries []dependencyEntry) map[string][]Dependency {
+	best := make(map[string]dependencyEntry)
+	for _, e := range entries {
+		if platformRank(c.platform, e.Platform) == 0 {
+			continue
+		}
+		current, ok := best[e.Name]
+		if !ok || c.newerDependencyEntry(e, current) {
+			best[e.Name] = e
+		}
+	}
+
+	result := make(map[string][]Dependency, len(best))
+	for name, e := range best {
+		deps := make([]Dependency, 0, len(e.Dependencies))
+		for _, pair := range e.Dependencies {
+			if len(pair) == 0 {
+				continue
+			}
+			dep := Dependency{Name: pair[0]}
+			if len(pair) > 1 {
+				dep.Requirements = pair[1]
+			}
+			deps = append(deps, dep)
+		}
+		result[name] = deps
+	}
+	return result
+}
+
+// newerDependencyEntry reports whether a should replace b as a gem's latest.
+func (c *Client) newerDependencyEntry(a, b dependencyEntry) bool {
+	if aPre, bPre := IsPrerelease(a.Number), IsPrerelease(b.Number); aPre != bPre {
+		return bPre
+	}
+	if cmp := c.compareVersions(a.Number, b.Number); cmp != 0 {
+		return cmp > 0
+	}
+	return platformRank(c.platform, a.Platform) > platformRank(c.platform, b.Platform)
+}
@@ -0,0 +1,184 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGetDependenciesBulk(t *testing.T) {
+	var mu sync.Mutex
+	var chunkSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/dependencies.json" {
+			http.NotFound(w, r)
+			return
+		}
+		names := strings.Split(r.URL.Query().Get("gems"), ",")
+		mu.Lock()
+		chunkSizes = append(chunkSizes, len(names))
+		mu.Unlock()
+
+		var entries []dependencyEntry
+		for _, name := range names {
+			switch name {
+			case "rails":
+				entries = append(entries,
+					dependencyEntry{Name: "rails", Number: "7.0.0", Platform: "ruby",
+						Dependencies: [][]string{{"actionpack", "= 7.0.0"}}},
+					dependencyEntry{Name: "rails", Number: "7.1.0", Platform: "ruby",
+						Dependencies: [][]string{{"actionpack", "= 7.1.0"}, {"railties", "= 7.1.0"}}},
+					dependencyEntry{Name: "rails", Number: "7.2.0.beta1", Platform: "ruby",
+						Dependencies: [][]string{{"actionpack", "= 7.2.0.beta1"}}})
+			case "nokogiri":
+				entries = append(entries,
+					dependencyEntry{Name: "nokogiri", Number: "1.16.0", Platform: "ruby",
+						Dependencies: [][]string{{"mini_portile2", "~> 2.8.2"}, {"racc", "~> 1.4"}}},
+					dependencyEntry{Name: "nokogiri", Number: "1.16.0", Platform: "x86_64-linux",
+						Dependencies: [][]string{{"racc", "~> 1.4"}}})
+			case "unknown":
+			default:
+				entries = append(entries, dependencyEntry{Name: name, Number: "1.0.0", Platform: "ruby"})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+	defer server.Close()
+
+	names := []string{"rails", "nokogiri", "unknown", "rails", ""}
+	for i := range 110 {
+		names = append(names, fmt.Sprintf("gem%d", i))
+	}
+
+	client := NewClientWithBaseURL(server.URL)
+	deps, err := client.GetDependenciesBulk(names)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slices.Sort(chunkSizes)
+	if want := []int{13, 50, 50}; !slices.Equal(chunkSizes, want) {
+		t.Errorf("chunk sizes = %v, want %v", chunkSizes, want)
+	}
+	if len(deps) != 112 {
+		t.Errorf("got %d gems, want 112", len(deps))
+	}
+	if _, ok := deps["unknown"]; ok {
+		t.Error("unknown gem should be absent")
+	}
+	if got := deps["rails"]; len(got) != 2 || got[1] != (Dependency{Name: "railties", Requirements: "= 7.1.0"}) {
+		t.Errorf("rails = %+v, want the 7.1.0 dependencies", got)
+	}
+	if got := deps["nokogiri"]; len(got) != 2 {
+		t.Errorf("nokogiri = %+v, want the ruby build's dependencies", got)
+	}
+	if got, ok := deps["gem0"]; !ok || len(got) != 0 {
+		t.Errorf("gem0 = %+v, %v; want no dependencies", got, ok)
+	}
+
+	linux := NewClientWithBaseURL(server.URL, WithPlatform("x86_64-linux"))
+	deps, err = linux.GetDependenciesBulk([]string{"nokogiri"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := deps["nokogiri"]; len(got) != 1 || got[0].Name != "racc" {
+		t.Errorf("nokogiri on x86_64-linux = %+v, want the platform build's dependencies", got)
+	}
+}
+
+func TestGetDependenciesBulk_CompactIndexFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info/rack":
+			_, _ = w.Write([]byte("---\n2.2.8 |checksum:aaa\n3.0.0 webrick:>= 1.8&< 2|checksum:bbb\n3.1.0.rc1 |checksum:ccc\n"))
+		case "/info/broken":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+	deps, err := client.GetDependenciesBulk([]string{"rack", "missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Dependency{{Name: "webrick", Requirements: ">= 1.8, < 2"}}
+	if !slices.Equal(deps["rack"], want) {
+		t.Errorf("rack = %+v, want %+v", deps["rack"], want)
+	}
+	if _, ok := deps["missing"]; ok {
+		t.Error("missing gem should be absent")
+	}
+
+	// Over the compact index each gem fails on its own
+	deps, err = client.GetDependenciesBulk([]string{"rack", "broken"})
+	var failed *DependenciesError
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected a *DependenciesError, got %v", err)
+	}
+	if len(failed.Gems) != 1 || failed.Gems["broken"] == nil {
+		t.Errorf("failed gems = %v, want only broken", failed.Gems)
+	}
+	if _, ok := deps["rack"]; !ok {
+		t.Error("expected rack alongside the failure")
+	}
+}
+
+func TestGetDependenciesBulk_PartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := strings.Split(r.URL.Query().Get("gems"), ",")
+		if slices.Contains(names, "broken") {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		var entries []dependencyEntry
+		for _, name := range names {
+			entries = append(entries, dependencyEntry{Name: name, Number: "1.0.0", Platform: "ruby"})
+		}
+		_ = json.NewEncoder(w).Encode(entries)
+	}))
+	defer server.Close()
+
+	names := []string{"broken"}
+	for i := range 60 {
+		names = append(names, fmt.Sprintf("gem%d", i))
+	}
+
+	client := NewClientWithBaseURL(server.URL)
+	deps, err := client.GetDependenciesBulk(names)
+	var failed *DependenciesError
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected a *DependenciesError, got %v", err)
+	}
+	// The first chunk (broken + gem0..gem48) failed; the second succeeded
+	if len(failed.Gems) != 50 {
+		t.Errorf("got %d failed gems, want the 50 in the failed chunk", len(failed.Gems))
+	}
+	for _, name := range []string{"broken", "gem0", "gem48"} {
+		var apiErr *APIError
+		if !errors.As(failed.Gems[name], &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Gems[%q] = %v, want the chunk's 500", name, failed.Gems[name])
+		}
+	}
+	if _, ok := failed.Gems["gem49"]; ok {
+		t.Error("gem49 was fetched and should not be reported")
+	}
+	if apiErr := new(APIError); !errors.As(err, &apiErr) {
+		t.Errorf("expected the error to unwrap to the *APIError, got %v", err)
+	}
+	if len(deps) != 11 {
+		t.Errorf("got %d gems, want the 11 from the successful chunk", len(deps))
+	}
+	if _, ok := deps["gem59"]; !ok {
+		t.Error("expected gem59 from the successful chunk")
+	}
+}
@@ -0,0 +1,73 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// DownloadStats holds download counts for a gem.
+type DownloadStats struct {
+	Total    int64            // Downloads across all versions
+	Versions map[string]int64 // Per-version counts, keyed like "1.0.0" or "1.0.0-java"
+}
+
+// versionDownloads is the subset of a /versions/<gem>.json entry needed for
+// download counts.
+type versionDownloads struct {
+	Number         string `json:"number"`
+	Platform       string `json:"platform"`
+	DownloadsCount int64  `json:"downloads_count"`
+}
+
+// GetGemDownloads returns the download counts of every published version of
+// a gem. Platform-specific builds are keyed with their platform suffix.
+// Total is the sum over published versions, so downloads of yanked versions
+// are not included.
+func (c *Client) GetGemDownloads(name string) (*DownloadStats, error) {
+	return c.GetGemDownloadsContext(context.Background(), name)
+}
+
+// GetGemDownloadsContext is like GetGemDownloads but aborts when ctx is canceled.
+func (c *Client) GetGemDownloadsContext(ctx context.Context, name string) (*DownloadStats, error) {
+	var versions []versionDownloads
+	endpoint := fmt.Sprintf("%s/versions/%s.json", c.baseURL, url.PathEscape(name))
+	if err := c.getJSON(ctx, endpoint, name, "gem downloads", &versions); err != nil {
+		return nil, err
+	}
+
+	stats := &DownloadStats{Versions: make(map[string]int64, len(versions))}
+	for _, v := range versions {
+		key := v.Number
+		if v.Platform != "" && v.Platform != "ruby" {
+			key += "-" + v.Platform
+		}
+		stats.Versions[key] = v.DownloadsCount
+		stats.Total += v.DownloadsCount
+	}
+	return stats, nil
+}
+
+// GetGemVersionDownloads returns download counts for one version using the
+// /downloads/<gem>-<version>.json endpoint. Total is the gem's overall count
+// as reported by the server, and Versions holds only the requested version.
+func (c *Client) GetGemVersionDownloads(name, version string) (*DownloadStats, error) {
+	return c.GetGemVersionDownloadsContext(context.Background(), name, version)
+}
+
+// GetGemVersionDownloadsContext is like GetGemVersionDownloads but aborts when ctx is canceled.
+func (c *Client) GetGemVersionDownloadsContext(ctx context.Context, name, version string) (*DownloadStats, error) {
+	var counts struct {
+		TotalDownloads   int64 `json:"total_downloads"`
+		VersionDownloads int64 `json:"version_downloads"`
+	}
+	endpoint := fmt.Sprintf("%s/downloads/%s-%s.json", c.baseURL, url.PathEscape(name), url.PathEscape(version))
+	if err := c.getJSON(ctx, endpoint, name, "gem downloads", &counts); err != nil {
+		return nil, err
+	}
+
+	return &DownloadStats{
+		Total:    counts.TotalDownloads,
+		Versions: map[string]int64{version: counts.VersionDownloads},
+	}, nil
+}
@@ -0,0 +1,66 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetGemDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/versions/nokogiri.json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`[
+			{"number": "1.16.0", "platform": "ruby", "downloads_count": 100},
+			{"number": "1.16.0", "platform": "java", "downloads_count": 20},
+			{"number": "1.15.0", "platform": "ruby", "downloads_count": 300}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	stats, err := client.GetGemDownloads("nokogiri")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Total != 420 {
+		t.Errorf("Total = %d, want 420", stats.Total)
+	}
+	want := map[string]int64{"1.16.0": 100, "1.16.0-java": 20, "1.15.0": 300}
+	for k, v := range want {
+		if stats.Versions[k] != v {
+			t.Errorf("Versions[%s] = %d, want %d", k, stats.Versions[k], v)
+		}
+	}
+	if len(stats.Versions) != len(want) {
+		t.Errorf("got %d versions, want %d", len(stats.Versions), len(want))
+	}
+}
+
+func TestGetGemVersionDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/downloads/rack-3.0.0.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"total_downloads": 5000, "version_downloads": 123}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	stats, err := client.GetGemVersionDownloads("rack", "3.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Total != 5000 || stats.Versions["3.0.0"] != 123 {
+		t.Errorf("got %+v", stats)
+	}
+
+	if _, err := client.GetGemVersionDownloads("rack", "0.0.1"); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+}
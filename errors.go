@@ -0,0 +1,209 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GemMovedError is returned when the server redirects a gem lookup to a
+// different gem name, signalling that the gem was renamed.
+type GemMovedError struct {
+	OldName string
+	NewName string
+}
+
+func (e *GemMovedError) Error() string {
+	return fmt.Sprintf("gem %s has moved to %s", e.OldName, e.NewName)
+}
+
+// ErrGemNotFound matches an *APIError for a 404 response.
+var ErrGemNotFound = errors.New("gem not found")
+
+// ErrUnauthorized matches an *APIError for a 401 response.
+var ErrUnauthorized = errors.New("unauthorized")
+
+// maxErrorBody caps how much of an error response is kept in APIError.Body.
+const maxErrorBody = 4 << 10
+
+// APIError is returned when the RubyGems API answers with an unexpected
+// status. Use errors.Is with ErrGemNotFound or ErrUnauthorized for the
+// common cases, or errors.As to inspect StatusCode directly (e.g. for 403).
+type APIError struct {
+	StatusCode int
+	GemName    string
+	Body       string // Start of the response body, for diagnostics
+}
+
+func (e *APIError) Error() string {
+	if e.GemName == "" {
+		return fmt.Sprintf("RubyGems API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("RubyGems API returned status %d for %s", e.StatusCode, e.GemName)
+}
+
+// Is reports whether the error matches one of the status sentinels.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrGemNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+// newAPIError builds an APIError from resp, keeping the start of its body.
+// Any credentials the request carried are masked, in case the server echoes
+// them back.
+func newAPIError(resp *http.Response, gemName string) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		GemName:    gemName,
+		Body:       redactSecrets(string(body), requestSecrets(resp.Request)),
+	}
+}
+
+// requestSecrets returns the secrets in a request's Authorization header:
+// its credentials as sent (a token, API key or encoded basic auth) and, for
+// basic auth, the decoded password, or the token sent as the username under
+// AuthSchemeBasic.
+func requestSecrets(req *http.Request) []string {
+	if req == nil {
+		return nil
+	}
+	var secrets []string
+	auth := req.Header.Get("Authorization")
+	if _, encoded, ok := strings.Cut(auth, " "); ok && encoded != "" {
+		secrets = append(secrets, encoded)
+	} else if auth != "" {
+		// A bare API key, as sent by PushGem
+		secrets = append(secrets, auth)
+	}
+	if username, password, ok := req.BasicAuth(); ok && password == basicTokenPassword {
+		secrets = append(secrets, username)
+	} else if ok && password != "" {
+		secrets = append(secrets, password)
+	}
+	return secrets
+}
+
+// redactSecrets masks every occurrence of the given secrets in s.
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redactedSecret)
+	}
+	return s
+}
+
+// ErrNoMatchingVersion is returned when no published version of a gem
+// satisfies the caller's version selection.
+var ErrNoMatchingVersion = errors.New("no matching version")
+
+// MissingCredentialsError is returned before sending a request to a host
+// configured with WithRequiredCredentialHosts when no credentials are set.
+type MissingCredentialsError struct {
+	Host string
+}
+
+func (e *MissingCredentialsError) Error() string {
+	return fmt.Sprintf("refusing unauthenticated request to %s: credentials are required for this host", e.Host)
+}
+
+// TooManyRedirectsError is returned when a request exceeds the redirect
+// limit set with WithMaxRedirects. Chain lists every URL visited, in order.
+type TooManyRedirectsError struct {
+	Max   int
+	Chain []string
+}
+
+func (e *TooManyRedirectsError) Error() string {
+	return fmt.Sprintf("too many redirects (max %d): %s", e.Max, strings.Join(e.Chain, " -> "))
+}
+
+// RateLimitedError is returned when the server answers 429 Too Many Requests
+// and the request is not retried, either because retries are disabled or
+// because they ran out. RetryAfter is the wait the server asked for, parsed
+// from the Retry-After header, or zero if it did not say.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("RubyGems API returned status 429: rate limited, retry after %s", e.RetryAfter)
+	}
+	return "RubyGems API returned status 429: rate limited"
+}
+
+// ChecksumMismatchError is returned when a downloaded file's SHA-256 does not
+// match the expected value. Checksums are lowercase hex.
+type ChecksumMismatchError struct {
+	File     string
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.File, e.Expected, e.Actual)
+}
+
+// DependenciesError is returned by GetDependenciesBulk when some gems could
+// not be fetched. Gems maps each of them to the error that lost it; gems
+// requested together in a failed chunk share their chunk's error.
+type DependenciesError struct {
+	Gems map[string]error
+
+	errs []error // Distinct errors, in the order they occurred
+}
+
+func (e *DependenciesError) Error() string {
+	names := slices.Sorted(maps.Keys(e.Gems))
+	return fmt.Sprintf("dependencies unavailable for %s: %v", strings.Join(names, ", "), errors.Join(e.errs...))
+}
+
+// Unwrap returns the underlying errors, so errors.Is and errors.As see
+// through to them.
+func (e *DependenciesError) Unwrap() []error {
+	return e.errs
+}
+
+// fail records err as the reason names could not be fetched.
+func (e *DependenciesError) fail(names []string, err error) {
+	if len(names) == 0 {
+		return
+	}
+	if e.Gems == nil {
+		e.Gems = make(map[string]error, len(names))
+	}
+	for _, name := range names {
+		e.Gems[name] = err
+	}
+	e.errs = append(e.errs, err)
+}
+
+// merge adds the failures recorded in other.
+func (e *DependenciesError) merge(other *DependenciesError) {
+	if other == nil {
+		return
+	}
+	if e.Gems == nil {
+		e.Gems = make(map[string]error, len(other.Gems))
+	}
+	maps.Copy(e.Gems, other.Gems)
+	e.errs = append(e.errs, other.errs...)
+}
+
+// orNil returns e, or nil if nothing failed.
+func (e *DependenciesError) orNil() error {
+	if len(e.Gems) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,23 @@
+package rubygemsclient
+
+import "context"
+
+// GemFetcher is the read-only gem lookup API of *Client. Code that only
+// queries gem metadata can accept a GemFetcher and be tested with a fake
+// instead of an HTTP server.
+type GemFetcher interface {
+	GetGemInfo(name, version string) (*GemInfo, error)
+	GetGemInfoContext(ctx context.Context, name, version string) (*GemInfo, error)
+
+	GetGemVersions(name string) ([]string, error)
+	GetGemVersionsContext(ctx context.Context, name string) ([]string, error)
+	GetGemVersionsDetailed(name string) ([]VersionInfo, error)
+	GetGemVersionsDetailedContext(ctx context.Context, name string) ([]VersionInfo, error)
+	GetLatestVersion(name string, opts ...ReleaseOption) (string, error)
+	GetLatestVersionContext(ctx context.Context, name string, opts ...ReleaseOption) (string, error)
+
+	GetMultipleGemInfo(requests []GemInfoRequest) []GemInfoResult
+	GetMultipleGemInfoContext(ctx context.Context, requests []GemInfoRequest) []GemInfoResult
+}
+
+var _ GemFetcher = (*Client)(nil)
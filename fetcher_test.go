@@ -0,0 +1,90 @@
+package rubygemsclient
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeFetcher serves gem info from a map, as a downstream test would.
+type fakeFetcher struct {
+	gems map[string]*GemInfo
+}
+
+func (f *fakeFetcher) GetGemInfo(name, version string) (*GemInfo, error) {
+	return f.GetGemInfoContext(context.Background(), name, version)
+}
+
+func (f *fakeFetcher) GetGemInfoContext(_ context.Context, name, _ string) (*GemInfo, error) {
+	info, ok := f.gems[name]
+	if !ok {
+		return nil, &APIError{StatusCode: 404}
+	}
+	return info, nil
+}
+
+func (f *fakeFetcher) GetGemVersions(name string) ([]string, error) {
+	return f.GetGemVersionsContext(context.Background(), name)
+}
+
+func (f *fakeFetcher) GetGemVersionsContext(ctx context.Context, name string) ([]string, error) {
+	info, err := f.GetGemInfoContext(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	return []string{info.Version}, nil
+}
+
+func (f *fakeFetcher) GetGemVersionsDetailed(name string) ([]VersionInfo, error) {
+	return f.GetGemVersionsDetailedContext(context.Background(), name)
+}
+
+func (f *fakeFetcher) GetGemVersionsDetailedContext(ctx context.Context, name string) ([]VersionInfo, error) {
+	info, err := f.GetGemInfoContext(ctx, name, "")
+	if err != nil {
+		return nil, err
+	}
+	return []VersionInfo{{Number: info.Version}}, nil
+}
+
+func (f *fakeFetcher) GetLatestVersion(name string, opts ...ReleaseOption) (string, error) {
+	return f.GetLatestVersionContext(context.Background(), name, opts...)
+}
+
+func (f *fakeFetcher) GetLatestVersionContext(ctx context.Context, name string, _ ...ReleaseOption) (string, error) {
+	info, err := f.GetGemInfoContext(ctx, name, "")
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+func (f *fakeFetcher) GetMultipleGemInfo(requests []GemInfoRequest) []GemInfoResult {
+	return f.GetMultipleGemInfoContext(context.Background(), requests)
+}
+
+func (f *fakeFetcher) GetMultipleGemInfoContext(ctx context.Context, requests []GemInfoRequest) []GemInfoResult {
+	results := make([]GemInfoResult, len(requests))
+	for i, req := range requests {
+		info, err := f.GetGemInfoContext(ctx, req.Name, req.Version)
+		results[i] = GemInfoResult{Request: req, Info: info, Error: err}
+	}
+	return results
+}
+
+func TestGemFetcher_Fake(t *testing.T) {
+	var fetcher GemFetcher = &fakeFetcher{gems: map[string]*GemInfo{
+		"rack": {Name: "rack", Version: "3.0.0"},
+	}}
+
+	// Code written against GemFetcher works the same with the fake
+	batch := BatchResult(fetcher.GetMultipleGemInfo([]GemInfoRequest{
+		{Name: "rack", Version: "3.0.0"},
+		{Name: "missing", Version: "1.0.0"},
+	}))
+	if len(batch.Successes()) != 1 || len(batch.Failures()) != 1 {
+		t.Errorf("Expected 1 success and 1 failure, got %+v", batch)
+	}
+	if latest, err := fetcher.GetLatestVersion("rack"); err != nil || latest != "3.0.0" {
+		t.Errorf("GetLatestVersion() = %q, %v", latest, err)
+	}
+}
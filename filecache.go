@@ -0,0 +1,83 @@
+package rubygemsclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileCache is a Cache that stores entries as files under a directory, so
+// separate processes (such as repeated CLI runs) can share them.
+// Entries older than the TTL are treated as missing. Write failures are
+// ignored: the cache only ever saves requests, it never fails them.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewFileCache creates a file cache in dir, which is created on first write.
+// A ttl of 0 keeps entries until they are overwritten.
+func NewFileCache(dir string, ttl time.Duration) *FileCache {
+	return &FileCache{dir: dir, ttl: ttl}
+}
+
+// path returns the file holding key. Keys are hashed because they contain
+// URLs and credential fingerprints that do not make valid file names.
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements Cache.
+func (f *FileCache) Get(key string) (*CachedResponse, bool) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry CachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if f.ttl > 0 && time.Since(entry.StoredAt) > f.ttl {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set implements Cache. The entry is written to a temporary file and renamed
+// into place, so concurrent readers never see a partial entry. Writers,
+// including other processes sharing the directory such as parallel CI jobs,
+// take turns under an advisory lock on the directory (flock, on Unix).
+func (f *FileCache) Set(key string, resp *CachedResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(f.dir, 0o700); err != nil {
+		return
+	}
+	unlock, err := lockDir(f.dir)
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	_ = os.Rename(tmp.Name(), f.path(key))
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package rubygemsclient
+
+// lockDir is a no-op where flock is unavailable; writes still go through a
+// temporary file and rename, so readers never see a partial entry.
+func lockDir(string) (unlock func(), err error) {
+	return func() {}, nil
+}
@@ -0,0 +1,147 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileCache_GetSet(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	cache := NewFileCache(dir, time.Hour)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("expected miss on empty cache")
+	}
+
+	cache.Set("key", &CachedResponse{Body: []byte("body"), ETag: `"e"`, StoredAt: time.Now()})
+
+	entry, ok := cache.Get("key")
+	if !ok {
+		t.Fatal("expected hit")
+	}
+	if string(entry.Body) != "body" || entry.ETag != `"e"` {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	// A second instance (another process) sees the same entry
+	if _, ok := NewFileCache(dir, time.Hour).Get("key"); !ok {
+		t.Error("expected hit from a fresh FileCache on the same directory")
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected exactly one file and no temp leftovers, got %d", len(files))
+	}
+}
+
+func TestFileCache_TTL(t *testing.T) {
+	dir := t.TempDir()
+
+	NewFileCache(dir, 0).Set("old", &CachedResponse{Body: []byte("x"), StoredAt: time.Now().Add(-2 * time.Hour)})
+
+	if _, ok := NewFileCache(dir, time.Hour).Get("old"); ok {
+		t.Error("expected expired entry to miss")
+	}
+	if _, ok := NewFileCache(dir, 0).Get("old"); !ok {
+		t.Error("expected entry to hit without a TTL")
+	}
+}
+
+func TestFileCache_CredentialScope(t *testing.T) {
+	var full atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Authorization") == "" {
+			_, _ = w.Write([]byte(`[{"number": "1.0.0"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number": "1.0.0"}, {"number": "2.0.0-private"}]`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	private := NewClientWithBaseURL(server.URL,
+		WithCache(NewFileCache(dir, time.Hour)),
+		WithCredentials(&Credentials{Token: "secret"}))
+	anonymous := NewClientWithBaseURL(server.URL, WithCache(NewFileCache(dir, time.Hour)))
+
+	versions, err := private.GetGemVersions("private-gem")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("private: got %v, %v", versions, err)
+	}
+
+	versions, err = anonymous.GetGemVersions("private-gem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("anonymous client was served the private response: %v", versions)
+	}
+	if got := full.Load(); got != 2 {
+		t.Errorf("expected a full response per credential scope, got %d", got)
+	}
+
+	// Same credentials in a new client reuse the cached entry
+	again := NewClientWithBaseURL(server.URL,
+		WithCache(NewFileCache(dir, time.Hour)),
+		WithCredentials(&Credentials{Token: "secret"}))
+	if versions, _ := again.GetGemVersions("private-gem"); len(versions) != 2 {
+		t.Errorf("expected cached private response, got %v", versions)
+	}
+	if got := full.Load(); got != 2 {
+		t.Errorf("expected revalidation instead of a full response, got %d full responses", got)
+	}
+}
+
+func TestFileCache_ConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each writer stands in for a separate process with its own FileCache
+	const writers = 8
+	bodies := make(map[string]bool, writers)
+	for i := range writers {
+		bodies[strings.Repeat(strconv.Itoa(i), 64<<10)] = true
+	}
+
+	var wg sync.WaitGroup
+	for body := range bodies {
+		wg.Go(func() {
+			cache := NewFileCache(dir, time.Hour)
+			for range 20 {
+				cache.Set("key", &CachedResponse{Body: []byte(body), StoredAt: time.Now()})
+			}
+		})
+	}
+	wg.Go(func() {
+		cache := NewFileCache(dir, time.Hour)
+		for range 200 {
+			if entry, ok := cache.Get("key"); ok && !bodies[string(entry.Body)] {
+				t.Errorf("read a torn entry of %d bytes", len(entry.Body))
+				return
+			}
+		}
+	})
+	wg.Wait()
+
+	entry, ok := NewFileCache(dir, time.Hour).Get("key")
+	if !ok || !bodies[string(entry.Body)] {
+		t.Error("expected one writer's complete entry")
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("expected exactly one file and no temp leftovers, got %d", len(files))
+	}
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package rubygemsclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileCache_SetWaitsForLock(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewFileCache(dir, time.Hour)
+
+	// Another process holds the lock
+	unlock, err := lockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cache.Set("key", &CachedResponse{Body: []byte("body"), StoredAt: time.Now()})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Set finished while another writer held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-done
+
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected the entry once the lock was released")
+	}
+}
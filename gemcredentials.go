@@ -0,0 +1,156 @@
+package rubygemsclient
+
+import (
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultAPIKeyName is the ~/.gem/credentials key holding the rubygems.org key.
+const defaultAPIKeyName = "rubygems_api_key"
+
+// GemCredentials holds API keys from the RubyGems CLI's credentials file,
+// the store `gem push` and `gem signin` use.
+// Ruby equivalent: Gem::ConfigFile#api_keys
+type GemCredentials struct {
+	keys map[string]string // Symbol keys without their leading colon; host keys as written
+}
+
+// LoadGemCredentials returns the parsed ~/.gem/credentials, or nil if the
+// file is missing or holds no keys. The file is read once per process, or
+// again after ReloadConfigs.
+func LoadGemCredentials() *GemCredentials {
+	configs.mu.Lock()
+	defer configs.mu.Unlock()
+	if !configs.gemCredentialsLoaded {
+		if path := gemCredentialsPath(); path != "" {
+			if data, err := os.ReadFile(path); err == nil {
+				configs.gemCredentials = parseGemCredentials(data)
+			}
+		}
+		configs.gemCredentialsLoaded = true
+	}
+	return configs.gemCredentials
+}
+
+// gemCredentialsPath returns the path of the RubyGems credentials file.
+func gemCredentialsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gem", "credentials")
+}
+
+// parseGemCredentials parses the credentials file, which Ruby writes as
+//
+//	---
+//	:rubygems_api_key: rubygems_0123abcd
+//	https://gems.example.com: 4567ef
+//
+// Symbol keys lose their leading colon. Entries whose value is not a scalar
+// are ignored; files that are not valid YAML are read line by line.
+func parseGemCredentials(data []byte) *GemCredentials {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return parseGemCredentialsLines(data)
+	}
+
+	creds := &GemCredentials{keys: make(map[string]string)}
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key, value := root.Content[i], root.Content[i+1]
+			if value.Kind == yaml.AliasNode {
+				value = value.Alias
+			}
+			if key.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			creds.add(key.Value, value.Value)
+		}
+	}
+	return creds.orNil()
+}
+
+// parseGemCredentialsLines reads "key: value" lines, for files a YAML
+// parser rejects.
+func parseGemCredentialsLines(data []byte) *GemCredentials {
+	creds := &GemCredentials{keys: make(map[string]string)}
+
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		key, value, ok := splitConfigLine(line)
+		if !ok {
+			continue
+		}
+		creds.add(key, trimQuotes(value))
+	}
+	return creds.orNil()
+}
+
+// add stores a non-empty key, dropping a symbol key's leading colon.
+func (g *GemCredentials) add(key, value string) {
+	if key = strings.TrimPrefix(key, ":"); key != "" && value != "" {
+		g.keys[key] = value
+	}
+}
+
+// orNil returns nil for credentials without keys.
+func (g *GemCredentials) orNil() *GemCredentials {
+	if len(g.keys) == 0 {
+		return nil
+	}
+	return g
+}
+
+// APIKeyFor returns the API key for a gem host, given as a host name or URL.
+// Keys stored under the host's URL win; rubygems.org also falls back to
+// :rubygems_api_key:. Returns "" if there is no key.
+func (g *GemCredentials) APIKeyFor(host string) string {
+	if g == nil {
+		return ""
+	}
+
+	want := apiKeyHost(host)
+	// Sorted so http:// and https:// entries for one host resolve stably
+	for _, key := range slices.Sorted(maps.Keys(g.keys)) {
+		if strings.Contains(key, "://") && apiKeyHost(key) == want {
+			return g.keys[key]
+		}
+	}
+	if want == apiKeyHost(DefaultHost) {
+		return g.keys[defaultAPIKeyName]
+	}
+	return ""
+}
+
+// apiKeyHost normalizes a host name or URL to a bare lowercase host.
+func apiKeyHost(hostOrURL string) string {
+	if u, err := url.Parse(hostOrURL); err == nil && u.Host != "" {
+		hostOrURL = u.Host
+	}
+	return normalizeHost(hostOrURL)
+}
+
+// APIKeyFor resolves the API key for a gem host the way the gem CLI does:
+// the GEM_HOST_API_KEY environment variable, then ~/.gem/credentials.
+// Returns "" if no key is configured.
+func APIKeyFor(host string) string {
+	if key := os.Getenv("GEM_HOST_API_KEY"); key != "" {
+		return key
+	}
+	return LoadGemCredentials().APIKeyFor(host)
+}
@@ -0,0 +1,103 @@
+package rubygemsclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const gemCredentialsFixture = `---
+:rubygems_api_key: rubygems_default
+https://gems.example.com: "example_key"
+http://localhost:9292: local_key
+:other_key: 'unused'
+`
+
+func TestParseGemCredentials(t *testing.T) {
+	creds := parseGemCredentials([]byte(gemCredentialsFixture))
+	if creds == nil {
+		t.Fatal("expected credentials")
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"rubygems.org", "rubygems_default"},
+		{"https://rubygems.org", "rubygems_default"},
+		{"gems.example.com", "example_key"},
+		{"https://GEMS.example.com/", "example_key"},
+		{"localhost:9292", "local_key"},
+		{"unknown.example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := creds.APIKeyFor(tt.host); got != tt.want {
+			t.Errorf("APIKeyFor(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+
+	if parseGemCredentials([]byte("---\n")) != nil {
+		t.Error("expected nil for a file without keys")
+	}
+}
+
+func TestParseGemCredentials_YAML(t *testing.T) {
+	// Forms a line parser gets wrong, as written by other YAML emitters
+	data := `---
+":rubygems_api_key": "rubygems_quoted" # signed in with gem signin
+https://gems.example.com: >-
+  folded_key
+https://alias.example.com: &shared shared_key
+https://other.example.com: *shared
+:scopes:
+  push_rubygem: true
+`
+	creds := parseGemCredentials([]byte(data))
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"rubygems.org", "rubygems_quoted"},
+		{"gems.example.com", "folded_key"},
+		{"alias.example.com", "shared_key"},
+		{"other.example.com", "shared_key"},
+	}
+	for _, tt := range tests {
+		if got := creds.APIKeyFor(tt.host); got != tt.want {
+			t.Errorf("APIKeyFor(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+	if _, ok := creds.keys["scopes"]; ok {
+		t.Error("expected the nested :scopes: mapping to be ignored")
+	}
+
+	// Not valid YAML: still read line by line
+	broken := parseGemCredentials([]byte(":rubygems_api_key: line_key\n\t: bad\n"))
+	if got := broken.APIKeyFor("rubygems.org"); got != "line_key" {
+		t.Errorf("line fallback APIKeyFor = %q, want line_key", got)
+	}
+}
+
+func TestAPIKeyFor(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GEM_HOST_API_KEY", "")
+	if err := os.MkdirAll(filepath.Join(home, ".gem"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".gem", "credentials"), []byte(gemCredentialsFixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := APIKeyFor("gems.example.com"); got != "example_key" {
+		t.Errorf("APIKeyFor from file = %q, want example_key", got)
+	}
+
+	t.Setenv("GEM_HOST_API_KEY", "env_key")
+	if got := APIKeyFor("gems.example.com"); got != "env_key" {
+		t.Errorf("expected GEM_HOST_API_KEY to win, got %q", got)
+	}
+}
@@ -0,0 +1,346 @@
+package rubygemsclient
+
+import (
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxGemspecSize caps the inflated size of a .gemspec.rz file.
+const maxGemspecSize = 8 << 20
+
+// Gemspec is a gem's full specification, as published by the server.
+// Ruby equivalent: Gem::Specification
+type Gemspec struct {
+	Name     string
+	Version  string
+	Platform string // "ruby" for the pure-Ruby build
+
+	Summary     string
+	Description string
+	Authors     []string
+	Email       []string
+	Homepage    string
+	Licenses    []string
+	Metadata    map[string]string
+	Date        time.Time
+
+	// Requirements on the installing Ruby and RubyGems, e.g. ">= 2.7.0".
+	// Empty when the gem accepts any version.
+	RequiredRubyVersion     string
+	RequiredRubyGemsVersion string
+
+	Dependencies DependencyCategories
+
+	// RubyGemsVersion is the RubyGems version that built the gem.
+	RubyGemsVersion      string
+	SpecificationVersion int
+}
+
+// Positions of the fields in the array written by Gem::Specification#_dump.
+const (
+	gemspecRubyGemsVersion = iota
+	gemspecSpecificationVersion
+	gemspecName
+	gemspecVersion
+	gemspecDate
+	gemspecSummary
+	gemspecRequiredRubyVersion
+	gemspecRequiredRubyGemsVersion
+	gemspecOriginalPlatform
+	gemspecDependencies
+	gemspecRubyforgeProject
+	gemspecEmail
+	gemspecAuthors
+	gemspecDescription
+	gemspecHomepage
+	gemspecHasRdoc
+	gemspecNewPlatform
+	gemspecLicenses
+	gemspecMetadata
+)
+
+// GetGemspec downloads and decodes the full specification of the pure-Ruby
+// build of a version from <host>/quick/Marshal.4.8/<name>-<version>.gemspec.rz.
+// Unlike GetGemInfo it includes fields the JSON API leaves out, such as
+// RequiredRubyVersion.
+func (c *Client) GetGemspec(name, version string) (*Gemspec, error) {
+	return c.GetGemspecContext(context.Background(), name, version)
+}
+
+// GetGemspecContext is like GetGemspec but aborts when ctx is canceled.
+func (c *Client) GetGemspecContext(ctx context.Context, name, version string) (*Gemspec, error) {
+	return c.GetGemspecForPlatformContext(ctx, name, version, "")
+}
+
+// GetGemspecForPlatform is like GetGemspec but fetches the specification of
+// the build for platform, such as "x86_64-linux" or "java".
+func (c *Client) GetGemspecForPlatform(name, version, platform string) (*Gemspec, error) {
+	return c.GetGemspecForPlatformContext(context.Background(), name, version, platform)
+}
+
+// GetGemspecForPlatformContext is like GetGemspecForPlatform but aborts when ctx is canceled.
+func (c *Client) GetGemspecForPlatformContext(ctx context.Context, name, version, platform string) (*Gemspec, error) {
+	file := strings.TrimSuffix(GemFileName(name, version, platform), ".gem") + ".gemspec.rz"
+	endpoint := c.serverRoot() + "/quick/Marshal.4.8/" + url.PathEscape(file)
+
+	req, err := c.newRequest(withGemName(ctx, name), "GET", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", MIMEMarshal)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch gemspec: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, name)
+	}
+
+	spec, err := readGemspec(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gemspec: %w", err)
+	}
+	return spec, nil
+}
+
+// readGemspec inflates and decodes a .gemspec.rz file.
+func readGemspec(r io.Reader) (*Gemspec, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, maxGemspecSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxGemspecSize {
+		return nil, fmt.Errorf("gemspec larger than %d bytes", maxGemspecSize)
+	}
+	return parseGemspec(data)
+}
+
+// parseGemspec decodes a marshaled Gem::Specification. Its _dump method
+// writes the fields as an array, itself marshaled into the outer stream.
+func parseGemspec(data []byte) (*Gemspec, error) {
+	v, err := unmarshalRuby(data)
+	if err != nil {
+		return nil, err
+	}
+	dumped, ok := v.(*rubyUserDef)
+	if !ok || dumped.Class != "Gem::Specification" {
+		return nil, errors.New("not a Gem::Specification")
+	}
+	v, err = unmarshalRuby(dumped.Data)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := v.([]any)
+	if !ok || len(fields) <= gemspecHomepage {
+		return nil, errors.New("malformed Gem::Specification")
+	}
+	field := func(i int) any {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return nil
+	}
+
+	spec := &Gemspec{
+		Name:                    specString(field(gemspecName)),
+		Version:                 specVersionString(field(gemspecVersion)),
+		Platform:                specPlatform(field(gemspecNewPlatform)),
+		Summary:                 specString(field(gemspecSummary)),
+		Description:             specString(field(gemspecDescription)),
+		Authors:                 specStrings(field(gemspecAuthors)),
+		Email:                   specStrings(field(gemspecEmail)),
+		Homepage:                specString(field(gemspecHomepage)),
+		Licenses:                specStrings(field(gemspecLicenses)),
+		Metadata:                specMetadata(field(gemspecMetadata)),
+		Date:                    specTime(field(gemspecDate)),
+		RequiredRubyVersion:     specRequirement(field(gemspecRequiredRubyVersion)),
+		RequiredRubyGemsVersion: specRequirement(field(gemspecRequiredRubyGemsVersion)),
+		Dependencies:            specDependencies(field(gemspecDependencies)),
+		RubyGemsVersion:         specString(field(gemspecRubyGemsVersion)),
+	}
+	if n, ok := field(gemspecSpecificationVersion).(int64); ok {
+		spec.SpecificationVersion = int(n)
+	}
+	if spec.Name == "" {
+		return nil, errors.New("gemspec has no name")
+	}
+	return spec, nil
+}
+
+// specString returns a String or Symbol field, or "" for nil.
+func specString(v any) string {
+	s, _ := rubyString(v)
+	return s
+}
+
+// specStrings returns a field that may be a single String or an Array of them.
+func specStrings(v any) []string {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := rubyString(item); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// specVersionString returns the text of a Gem::Version.
+func specVersionString(v any) string {
+	switch v := v.(type) {
+	case *rubyUserMarshal:
+		// marshal_dump writes [version]
+		if data, ok := v.Data.([]any); ok && len(data) > 0 {
+			return specString(data[0])
+		}
+	case *rubyObject:
+		return specString(v.IVars["@version"])
+	}
+	return specString(v)
+}
+
+// specRequirement formats a Gem::Requirement as "op version" constraints
+// joined with ", ". The catch-all ">= 0" is reported as "".
+func specRequirement(v any) string {
+	var reqs any
+	switch v := v.(type) {
+	case *rubyUserMarshal:
+		// marshal_dump writes [requirements]
+		if data, ok := v.Data.([]any); ok && len(data) > 0 {
+			reqs = data[0]
+		}
+	case *rubyObject:
+		reqs = v.IVars["@requirements"]
+	}
+	list, _ := reqs.([]any)
+
+	var parts []string
+	for _, item := range list {
+		pair, ok := item.([]any)
+		if !ok || len(pair) != 2 {
+			continue
+		}
+		op, version := specString(pair[0]), specVersionString(pair[1])
+		parts = append(parts, op+" "+version)
+	}
+	if len(parts) == 1 && parts[0] == ">= 0" {
+		return ""
+	}
+	return strings.Join(parts, ", ")
+}
+
+// specPlatform returns a platform as written in file names: "ruby", or the
+// non-nil parts of a Gem::Platform joined with "-".
+func specPlatform(v any) string {
+	if obj, ok := v.(*rubyObject); ok {
+		var parts []string
+		for _, name := range []string{"@cpu", "@os", "@version"} {
+			if s := specString(obj.IVars[name]); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, "-")
+	}
+	if s := specString(v); s != "" {
+		return s
+	}
+	return "ruby"
+}
+
+// specDependencies sorts Gem::Dependency objects into runtime and
+// development dependencies.
+func specDependencies(v any) DependencyCategories {
+	var deps DependencyCategories
+	list, _ := v.([]any)
+	for _, item := range list {
+		obj, ok := item.(*rubyObject)
+		if !ok || obj.Class != "Gem::Dependency" {
+			continue
+		}
+		requirement := obj.IVars["@requirement"]
+		if requirement == nil {
+			// Specs from old RubyGems versions
+			requirement = obj.IVars["@version_requirements"]
+		}
+		dep := Dependency{
+			Name:         specString(obj.IVars["@name"]),
+			Requirements: specRequirement(requirement),
+		}
+		if dep.Requirements == "" {
+			dep.Requirements = ">= 0"
+		}
+		if specString(obj.IVars["@type"]) == "development" {
+			deps.Development = append(deps.Development, dep)
+		} else {
+			deps.Runtime = append(deps.Runtime, dep)
+		}
+	}
+	return deps
+}
+
+// specMetadata returns a Hash of String keys and values.
+func specMetadata(v any) map[string]string {
+	h, ok := v.(*rubyHash)
+	if !ok || len(h.Keys) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h.Keys))
+	for i, k := range h.Keys {
+		key, ok := rubyString(k)
+		if !ok {
+			continue
+		}
+		if value, ok := rubyString(h.Values[i]); ok {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// specTime decodes a Time written by Time#_dump: two little-endian words
+// packing the UTC date and time fields.
+func specTime(v any) time.Time {
+	dumped, ok := v.(*rubyUserDef)
+	if !ok || dumped.Class != "Time" || len(dumped.Data) < 8 {
+		return time.Time{}
+	}
+	p := binary.LittleEndian.Uint32(dumped.Data[0:4])
+	s := binary.LittleEndian.Uint32(dumped.Data[4:8])
+	if p&(1<<31) == 0 {
+		// Old format: seconds and microseconds since the epoch
+		return time.Unix(int64(p), int64(s)*int64(time.Microsecond)).UTC()
+	}
+	year := int(p>>14&0xffff) + 1900
+	month := time.Month(p>>10&0xf + 1)
+	day := int(p >> 5 & 0x1f)
+	hour := int(p & 0x1f)
+	minute := int(s >> 26 & 0x3f)
+	sec := int(s >> 20 & 0x3f)
+	usec := int(s & 0xfffff)
+	return time.Date(year, month, day, hour, minute, sec, usec*int(time.Microsecond), time.UTC)
+}
@@ -0,0 +1,179 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// gemspecFixture returns the .gemspec.rz file RubyGems would publish for a
+// rack-like gem, with the given platform: nil for "ruby", or
+// [cpu, os, version] for a Gem::Platform.
+func gemspecFixture(t *testing.T, platform []string) []byte {
+	t.Helper()
+
+	// Time#_dump of 2024-01-02 03:04:05 UTC
+	var date [8]byte
+	binary.LittleEndian.PutUint32(date[0:4], 1<<31|1<<30|(2024-1900)<<14|0<<10|2<<5|3)
+	binary.LittleEndian.PutUint32(date[4:8], 4<<26|5<<20)
+
+	inner := newMarshalBuilder().array(19)
+	inner.str("3.5.3").int(4).str("rack")
+	inner.userMarshal("Gem::Version").array(1).str("3.0.0")
+	inner.userDef("Time", date[:])
+	inner.str("A modular Ruby webserver interface.")
+	inner.userMarshal("Gem::Requirement").array(1).array(1).array(2).str(">=")
+	inner.userMarshal("Gem::Version").array(1).str("2.4.0")
+	inner.userMarshal("Gem::Requirement").array(1).array(1).array(2).str(">=")
+	inner.userMarshal("Gem::Version").array(1).str("0")
+	inner.str("ruby")
+	inner.array(2)
+	inner.object("Gem::Dependency", 4).sym("@name").str("webrick").
+		sym("@requirement").userMarshal("Gem::Requirement").array(1).array(2).
+		array(2).str("~>").userMarshal("Gem::Version").array(1).str("1.8").
+		array(2).str("<").userMarshal("Gem::Version").array(1).str("2")
+	inner.sym("@type").sym("runtime").sym("@prerelease").null()
+	inner.object("Gem::Dependency", 3).sym("@name").str("minitest").
+		sym("@requirement").userMarshal("Gem::Requirement").array(1).array(1).
+		array(2).str(">=").userMarshal("Gem::Version").array(1).str("0")
+	inner.sym("@type").sym("development")
+	inner.null()
+	inner.str("leah@vuxu.org")
+	inner.array(2).str("Leah Neukirchen").str("Rack Contributors")
+	inner.str("Rack provides a minimal interface.")
+	inner.str("https://github.com/rack/rack")
+	inner.buf.WriteByte('T')
+	if platform == nil {
+		inner.str("ruby")
+	} else {
+		inner.object("Gem::Platform", 3)
+		for i, name := range []string{"@cpu", "@os", "@version"} {
+			inner.sym(name)
+			if platform[i] == "" {
+				inner.null()
+			} else {
+				inner.str(platform[i])
+			}
+		}
+	}
+	inner.array(1).str("MIT")
+	inner.hash(1).str("changelog_uri").str("https://github.com/rack/rack/blob/main/CHANGELOG.md")
+
+	outer := newMarshalBuilder().userDef("Gem::Specification", inner.bytes())
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(outer.bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newGemspecServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	files := map[string][]byte{
+		"/quick/Marshal.4.8/rack-3.0.0.gemspec.rz":              gemspecFixture(t, nil),
+		"/quick/Marshal.4.8/rack-3.0.0-x86_64-linux.gemspec.rz": gemspecFixture(t, []string{"x86_64", "linux", ""}),
+		"/quick/Marshal.4.8/rack-0.0.1.gemspec.rz":              []byte("not zlib"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetGemspec(t *testing.T) {
+	server := newGemspecServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	spec, err := client.GetGemspec("rack", "3.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Gemspec{
+		Name:        "rack",
+		Version:     "3.0.0",
+		Platform:    "ruby",
+		Summary:     "A modular Ruby webserver interface.",
+		Description: "Rack provides a minimal interface.",
+		Authors:     []string{"Leah Neukirchen", "Rack Contributors"},
+		Email:       []string{"leah@vuxu.org"},
+		Homepage:    "https://github.com/rack/rack",
+		Licenses:    []string{"MIT"},
+		Metadata: map[string]string{
+			"changelog_uri": "https://github.com/rack/rack/blob/main/CHANGELOG.md",
+		},
+		Date:                time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		RequiredRubyVersion: ">= 2.4.0",
+		Dependencies: DependencyCategories{
+			Runtime:     []Dependency{{Name: "webrick", Requirements: "~> 1.8, < 2"}},
+			Development: []Dependency{{Name: "minitest", Requirements: ">= 0"}},
+		},
+		RubyGemsVersion:      "3.5.3",
+		SpecificationVersion: 4,
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Errorf("GetGemspec() =\n%+v\nwant\n%+v", spec, want)
+	}
+}
+
+func TestGetGemspecForPlatform(t *testing.T) {
+	server := newGemspecServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	spec, err := client.GetGemspecForPlatform("rack", "3.0.0", "x86_64-linux")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Platform != "x86_64-linux" {
+		t.Errorf("Platform = %q, want x86_64-linux", spec.Platform)
+	}
+}
+
+func TestGetGemspec_Errors(t *testing.T) {
+	server := newGemspecServer(t)
+	client := NewClientWithBaseURL(server.URL)
+
+	if _, err := client.GetGemspec("rack", "9.9.9"); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+	if _, err := client.GetGemspec("rack", "0.0.1"); err == nil {
+		t.Error("expected an error for a corrupt gemspec")
+	}
+}
+
+func TestParseGemspec_RejectsOtherObjects(t *testing.T) {
+	data := newMarshalBuilder().userDef("Time", make([]byte, 8)).bytes()
+	if _, err := parseGemspec(data); err == nil {
+		t.Error("expected an error for a non-gemspec object")
+	}
+}
+
+func TestSpecTime_LegacyFormat(t *testing.T) {
+	// The original Time#_dump format: seconds and microseconds since the epoch
+	var data [8]byte
+	binary.LittleEndian.PutUint32(data[0:4], 1000000000)
+	binary.LittleEndian.PutUint32(data[4:8], 250000)
+
+	got := specTime(&rubyUserDef{Class: "Time", Data: data[:]})
+	want := time.Date(2001, time.September, 9, 1, 46, 40, 250000000, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("specTime() = %v, want %v", got, want)
+	}
+}
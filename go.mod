@@ -2,4 +2,8 @@ module github.com/contriboss/rubygems-client-go
 
 go 1.25
 
-require github.com/magefile/mage v1.15.0
+require (
+	github.com/magefile/mage v1.15.0
+	golang.org/x/time v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
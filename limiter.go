@@ -0,0 +1,32 @@
+package rubygemsclient
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// WithRateLimit spaces out requests with a token bucket: at most rps requests
+// per second on average, with bursts of up to burst. Every request the client
+// sends, including retries, waits for a token first, so bursty batch lookups
+// are smoothed out instead of tripping the server's 429s. Waiting honors the
+// request's context. A non-positive rps disables the limit (the default); a
+// burst below 1 is treated as 1.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		c.limiter = rate.NewLimiter(rate.Limit(rps), max(burst, 1))
+	}
+}
+
+// waitForToken blocks until the rate limiter allows another request, or
+// returns an error if ctx ends first.
+func (c *Client) waitForToken(ctx context.Context) error {
+	if c.limiter == nil {
+		return nil
+	}
+	return c.limiter.Wait(ctx)
+}
@@ -0,0 +1,68 @@
+package rubygemsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem", Version: "1.0.0"})
+	}))
+	defer server.Close()
+
+	// 20/s with a burst of 2: six requests need four more tokens, ~200ms
+	client := NewClientWithBaseURL(server.URL, WithRateLimit(20, 2))
+
+	start := time.Now()
+	requests := make([]GemInfoRequest, 6)
+	for i := range requests {
+		requests[i] = GemInfoRequest{Name: "gem"}
+	}
+	if err := client.GetMultipleGemInfoBatch(requests).Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("six requests took %v, want them spread over ~200ms", elapsed)
+	}
+}
+
+func TestWithRateLimit_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "gem", Version: "1.0.0"})
+	}))
+	defer server.Close()
+
+	// One request per minute: the second has to wait far past the deadline
+	client := NewClientWithBaseURL(server.URL, WithRateLimit(1.0/60, 1))
+	if _, err := client.GetGemInfo("gem", ""); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := client.GetGemInfoContext(ctx, "gem", "")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancellation took %v", elapsed)
+	}
+}
+
+func TestWithRateLimit_Disabled(t *testing.T) {
+	client := NewClient(WithRateLimit(10, 1), WithRateLimit(0, 0))
+	if client.limiter != nil {
+		t.Error("WithRateLimit(0, 0) should disable the limiter")
+	}
+}
@@ -0,0 +1,38 @@
+package rubygemsclient
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// WithLogger calls log after every HTTP request the client sends, retries
+// included, with the request's method and URL, the response status (0 if no
+// response was received) and how long the round trip took. The URL never
+// carries credentials: any user info is removed and auth headers are not
+// passed on. Hooks run synchronously on the requesting goroutine, possibly
+// from several at once, so they must be quick and safe for concurrent use.
+func WithLogger(log func(ctx context.Context, method, url string, status int, dur time.Duration)) ClientOption {
+	return func(c *Client) {
+		c.logRequest = log
+	}
+}
+
+// WithRequestStartHook calls start just before every HTTP request the client
+// sends, with the same redacted URL WithLogger reports.
+func WithRequestStartHook(start func(ctx context.Context, method, url string)) ClientOption {
+	return func(c *Client) {
+		c.requestStart = start
+	}
+}
+
+// redactURL returns u as a string without its user info. Unlike
+// url.URL.Redacted it also drops a lone username, which may be a token.
+func redactURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	stripped := *u
+	stripped.User = nil
+	return stripped.String()
+}
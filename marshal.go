@@ -0,0 +1,513 @@
+package rubygemsclient
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// This file decodes Ruby's Marshal format, version 4.8, into plain Go values.
+// Ruby equivalent: Marshal.load
+//
+// Values map as follows:
+//
+//	nil, true, false    nil, true, false
+//	Integer             int64, or *big.Int when it does not fit
+//	Float               float64
+//	String              string (raw bytes; encodings are ignored)
+//	Symbol              rubySymbol
+//	Array               []any
+//	Hash                *rubyHash
+//	Object, Struct       *rubyObject
+//	_dump               *rubyUserDef
+//	marshal_dump        *rubyUserMarshal
+//
+// Classes, modules and regexps are read far enough to keep the stream in
+// sync and returned as a *rubyObject naming their class.
+
+// marshalMajor and marshalMinor are the only format version accepted.
+const (
+	marshalMajor = 4
+	marshalMinor = 8
+)
+
+// errMarshalDepth guards against maliciously deep nesting.
+var errMarshalDepth = errors.New("marshal: nesting too deep")
+
+// maxMarshalDepth limits how deeply values may nest.
+const maxMarshalDepth = 256
+
+// rubySymbol is a decoded Ruby Symbol.
+type rubySymbol string
+
+// rubyHash is a decoded Ruby Hash. Keys keep their order and may be any
+// decoded value, so they are stored as pairs rather than in a Go map.
+type rubyHash struct {
+	Keys   []any
+	Values []any
+}
+
+// get returns the value for a String or Symbol key.
+func (h *rubyHash) get(key string) (any, bool) {
+	if h == nil {
+		return nil, false
+	}
+	for i, k := range h.Keys {
+		if s, ok := rubyString(k); ok && s == key {
+			return h.Values[i], true
+		}
+	}
+	return nil, false
+}
+
+// rubyObject is a plain Ruby object: its class and instance variables, keyed
+// with their leading "@".
+type rubyObject struct {
+	Class string
+	IVars map[string]any
+}
+
+// rubyUserDef is an object written by a class's _dump method.
+type rubyUserDef struct {
+	Class string
+	Data  []byte
+}
+
+// rubyUserMarshal is an object written by a class's marshal_dump method.
+type rubyUserMarshal struct {
+	Class string
+	Data  any
+}
+
+// unmarshalRuby decodes one Marshal 4.8 value from data.
+func unmarshalRuby(data []byte) (any, error) {
+	return newMarshalDecoder(bufio.NewReader(bytes.NewReader(data))).decode()
+}
+
+// marshalDecoder reads one Marshal stream.
+type marshalDecoder struct {
+	r       *bufio.Reader
+	symbols []rubySymbol
+	objects []any
+	depth   int
+}
+
+func newMarshalDecoder(r *bufio.Reader) *marshalDecoder {
+	return &marshalDecoder{r: r}
+}
+
+// decode checks the version header and reads the top-level value.
+func (d *marshalDecoder) decode() (any, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		return nil, fmt.Errorf("marshal: reading header: %w", err)
+	}
+	if header[0] != marshalMajor || header[1] != marshalMinor {
+		return nil, fmt.Errorf("marshal: unsupported version %d.%d", header[0], header[1])
+	}
+	return d.value()
+}
+
+// value reads one tagged value.
+func (d *marshalDecoder) value() (any, error) {
+	d.depth++
+	defer func() { d.depth-- }()
+	if d.depth > maxMarshalDepth {
+		return nil, errMarshalDepth
+	}
+
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+
+	switch tag {
+	case '0':
+		return nil, nil
+	case 'T':
+		return true, nil
+	case 'F':
+		return false, nil
+	case 'i':
+		n, err := d.fixnum()
+		return int64(n), err
+	case ':':
+		return d.newSymbol()
+	case ';':
+		return d.symlink()
+	case '@':
+		n, err := d.fixnum()
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || n >= len(d.objects) {
+			return nil, fmt.Errorf("marshal: bad object link %d", n)
+		}
+		return d.objects[n], nil
+	case 'I':
+		// A value followed by instance variables, e.g. a String's encoding
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		return v, d.skipIVars()
+	case 'e':
+		// An object extended with a module: skip the module name
+		if _, err := d.symbol(); err != nil {
+			return nil, err
+		}
+		return d.value()
+	case '"':
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return d.register(string(b)), nil
+	case 'f':
+		return d.float()
+	case 'l':
+		return d.bignum()
+	case '[':
+		return d.array()
+	case '{', '}':
+		return d.hash(tag == '}')
+	case 'o':
+		return d.object()
+	case 'u':
+		return d.userDef()
+	case 'U':
+		return d.userMarshal()
+	case 'C':
+		// A String, Array or Hash subclass: keep the underlying value
+		if _, err := d.symbol(); err != nil {
+			return nil, err
+		}
+		return d.value()
+	case 'c', 'm', 'M':
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return d.register(&rubyObject{Class: string(b)}), nil
+	case '/':
+		if _, err := d.bytes(); err != nil {
+			return nil, err
+		}
+		if _, err := d.r.ReadByte(); err != nil { // Options
+			return nil, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+		}
+		return d.register(&rubyObject{Class: "Regexp"}), nil
+	case 'S':
+		// A Struct: its members are read like instance variables
+		return d.object()
+	default:
+		return nil, fmt.Errorf("marshal: unsupported type %q", tag)
+	}
+}
+
+// register adds v to the object table so later links can refer to it.
+func (d *marshalDecoder) register(v any) any {
+	d.objects = append(d.objects, v)
+	return v
+}
+
+// reserve claims an object table slot for a value still being read.
+func (d *marshalDecoder) reserve() int {
+	d.objects = append(d.objects, nil)
+	return len(d.objects) - 1
+}
+
+// fixnum reads Marshal's variable-length integer encoding.
+func (d *marshalDecoder) fixnum() (int, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return 0, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	c := int(int8(b))
+	switch {
+	case c == 0:
+		return 0, nil
+	case c >= 5:
+		return c - 5, nil
+	case c <= -5:
+		return c + 5, nil
+	}
+
+	size := c
+	if size < 0 {
+		size = -size
+	}
+	var buf [4]byte
+	if _, err := io.ReadFull(d.r, buf[:size]); err != nil {
+		return 0, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	n := 0
+	for i := size - 1; i >= 0; i-- {
+		n = n<<8 | int(buf[i])
+	}
+	if c < 0 {
+		n -= 1 << (8 * size)
+	}
+	return n, nil
+}
+
+// bytes reads a length-prefixed byte string.
+func (d *marshalDecoder) bytes() ([]byte, error) {
+	n, err := d.fixnum()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("marshal: bad length %d", n)
+	}
+	// Grow with the data actually read rather than trusting the length
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	return buf.Bytes(), nil
+}
+
+// newSymbol reads a symbol's name and adds it to the symbol table.
+func (d *marshalDecoder) newSymbol() (rubySymbol, error) {
+	b, err := d.bytes()
+	if err != nil {
+		return "", err
+	}
+	sym := rubySymbol(b)
+	d.symbols = append(d.symbols, sym)
+	return sym, nil
+}
+
+// symlink reads a reference to an earlier symbol.
+func (d *marshalDecoder) symlink() (rubySymbol, error) {
+	n, err := d.fixnum()
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n >= len(d.symbols) {
+		return "", fmt.Errorf("marshal: bad symbol link %d", n)
+	}
+	return d.symbols[n], nil
+}
+
+// symbol reads a symbol where one is required, such as a class name.
+// Symbols with an encoding are wrapped in 'I'.
+func (d *marshalDecoder) symbol() (rubySymbol, error) {
+	tag, err := d.r.ReadByte()
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	switch tag {
+	case ':':
+		return d.newSymbol()
+	case ';':
+		return d.symlink()
+	case 'I':
+		sym, err := d.symbol()
+		if err != nil {
+			return "", err
+		}
+		return sym, d.skipIVars()
+	default:
+		return "", fmt.Errorf("marshal: expected symbol, got %q", tag)
+	}
+}
+
+// ivars reads a count of instance variables and their values.
+func (d *marshalDecoder) ivars() (map[string]any, error) {
+	n, err := d.fixnum()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("marshal: bad count %d", n)
+	}
+	vars := make(map[string]any, min(n, 64))
+	for range n {
+		name, err := d.symbol()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		vars[string(name)] = v
+	}
+	return vars, nil
+}
+
+// skipIVars reads and discards instance variables.
+func (d *marshalDecoder) skipIVars() error {
+	_, err := d.ivars()
+	return err
+}
+
+// float reads a Float, written as its decimal text.
+func (d *marshalDecoder) float() (any, error) {
+	b, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var f float64
+	switch s := string(b); s {
+	case "inf":
+		f = math.Inf(1)
+	case "-inf":
+		f = math.Inf(-1)
+	case "nan":
+		f = math.NaN()
+	default:
+		f, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("marshal: bad float %q", s)
+		}
+	}
+	return d.register(f), nil
+}
+
+// bignum reads an Integer too large for a fixnum.
+func (d *marshalDecoder) bignum() (any, error) {
+	sign, err := d.r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	shorts, err := d.fixnum()
+	if err != nil {
+		return nil, err
+	}
+	if shorts < 0 {
+		return nil, fmt.Errorf("marshal: bad bignum length %d", shorts)
+	}
+	// Grow with the data actually read rather than trusting the length
+	var data bytes.Buffer
+	if _, err := io.CopyN(&data, d.r, 2*int64(shorts)); err != nil {
+		return nil, fmt.Errorf("marshal: %w", io.ErrUnexpectedEOF)
+	}
+	buf := data.Bytes()
+	// Little-endian on the wire; big.Int wants big-endian
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	n := new(big.Int).SetBytes(buf)
+	if sign == '-' {
+		n.Neg(n)
+	}
+	if n.IsInt64() {
+		return d.register(n.Int64()), nil
+	}
+	return d.register(n), nil
+}
+
+// array reads an Array.
+func (d *marshalDecoder) array() (any, error) {
+	slot := d.reserve()
+	n, err := d.fixnum()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("marshal: bad count %d", n)
+	}
+	items := make([]any, 0, min(n, 1024))
+	for range n {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	d.objects[slot] = items
+	return items, nil
+}
+
+// hash reads a Hash, followed by its default value when withDefault is set.
+func (d *marshalDecoder) hash(withDefault bool) (any, error) {
+	h := &rubyHash{}
+	d.register(h)
+	n, err := d.fixnum()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("marshal: bad count %d", n)
+	}
+	for range n {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		h.Keys = append(h.Keys, k)
+		h.Values = append(h.Values, v)
+	}
+	if withDefault {
+		if _, err := d.value(); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// object reads a plain object: its class and instance variables.
+func (d *marshalDecoder) object() (any, error) {
+	class, err := d.symbol()
+	if err != nil {
+		return nil, err
+	}
+	obj := &rubyObject{Class: string(class)}
+	d.register(obj)
+	obj.IVars, err = d.ivars()
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// userDef reads an object written by _dump.
+func (d *marshalDecoder) userDef() (any, error) {
+	class, err := d.symbol()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return d.register(&rubyUserDef{Class: string(class), Data: data}), nil
+}
+
+// userMarshal reads an object written by marshal_dump.
+func (d *marshalDecoder) userMarshal() (any, error) {
+	class, err := d.symbol()
+	if err != nil {
+		return nil, err
+	}
+	obj := &rubyUserMarshal{Class: string(class)}
+	d.register(obj)
+	obj.Data, err = d.value()
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// rubyString returns a String or Symbol value as a Go string.
+func rubyString(v any) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case rubySymbol:
+		return string(s), true
+	}
+	return "", false
+}
@@ -0,0 +1,306 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// marshalBuilder writes Marshal 4.8 fixtures, standing in for Ruby's
+// Marshal.dump. Containers are written as a header followed by their items.
+type marshalBuilder struct {
+	buf     bytes.Buffer
+	symbols map[string]int
+}
+
+func newMarshalBuilder() *marshalBuilder {
+	b := &marshalBuilder{symbols: make(map[string]int)}
+	b.buf.Write([]byte{marshalMajor, marshalMinor})
+	return b
+}
+
+func (b *marshalBuilder) bytes() []byte { return b.buf.Bytes() }
+
+func (b *marshalBuilder) long(n int) {
+	switch {
+	case n == 0:
+		b.buf.WriteByte(0)
+	case n > 0 && n < 123:
+		b.buf.WriteByte(byte(n + 5))
+	case n < 0 && n > -124:
+		b.buf.WriteByte(byte(n - 5))
+	default:
+		var out []byte
+		for range 4 {
+			out = append(out, byte(n))
+			n >>= 8
+			if n == 0 || n == -1 {
+				break
+			}
+		}
+		size := len(out)
+		if n == -1 {
+			size = -size
+		}
+		b.buf.WriteByte(byte(size))
+		b.buf.Write(out)
+	}
+}
+
+func (b *marshalBuilder) raw(s string) {
+	b.long(len(s))
+	b.buf.WriteString(s)
+}
+
+func (b *marshalBuilder) null() *marshalBuilder { b.buf.WriteByte('0'); return b }
+
+func (b *marshalBuilder) int(n int) *marshalBuilder {
+	b.buf.WriteByte('i')
+	b.long(n)
+	return b
+}
+
+func (b *marshalBuilder) sym(s string) *marshalBuilder {
+	if i, ok := b.symbols[s]; ok {
+		b.buf.WriteByte(';')
+		b.long(i)
+		return b
+	}
+	b.symbols[s] = len(b.symbols)
+	b.buf.WriteByte(':')
+	b.raw(s)
+	return b
+}
+
+// str writes a UTF-8 String, which Ruby wraps with an encoding ivar.
+func (b *marshalBuilder) str(s string) *marshalBuilder {
+	b.buf.WriteByte('I')
+	b.buf.WriteByte('"')
+	b.raw(s)
+	b.long(1)
+	b.sym("E")
+	b.buf.WriteByte('T')
+	return b
+}
+
+func (b *marshalBuilder) array(n int) *marshalBuilder {
+	b.buf.WriteByte('[')
+	b.long(n)
+	return b
+}
+
+func (b *marshalBuilder) hash(n int) *marshalBuilder {
+	b.buf.WriteByte('{')
+	b.long(n)
+	return b
+}
+
+// object writes an object header; follow it with n ivar symbols and values.
+func (b *marshalBuilder) object(class string, n int) *marshalBuilder {
+	b.buf.WriteByte('o')
+	b.sym(class)
+	b.long(n)
+	return b
+}
+
+func (b *marshalBuilder) userDef(class string, data []byte) *marshalBuilder {
+	b.buf.WriteByte('u')
+	b.sym(class)
+	b.raw(string(data))
+	return b
+}
+
+// userMarshal writes a marshal_dump header; follow it with the dumped value.
+func (b *marshalBuilder) userMarshal(class string) *marshalBuilder {
+	b.buf.WriteByte('U')
+	b.sym(class)
+	return b
+}
+
+func (b *marshalBuilder) link(n int) *marshalBuilder {
+	b.buf.WriteByte('@')
+	b.long(n)
+	return b
+}
+
+func TestMarshalBuilder_LongMatchesDecoder(t *testing.T) {
+	for _, n := range []int{0, 1, 122, 123, 255, 256, 65535, 65536, 1 << 30, -1, -123, -124, -256, -257, -(1 << 30)} {
+		b := newMarshalBuilder().int(n)
+		got, err := unmarshalRuby(b.bytes())
+		if err != nil {
+			t.Fatalf("%d: %v", n, err)
+		}
+		if got != int64(n) {
+			t.Errorf("round trip of %d = %v", n, got)
+		}
+	}
+}
+
+func TestUnmarshalRuby_Fixnum(t *testing.T) {
+	// Encodings as written by Ruby's Marshal.dump
+	tests := []struct {
+		data []byte
+		want int64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x06}, 1},
+		{[]byte{0x7f}, 122},
+		{[]byte{0x01, 0x7b}, 123},
+		{[]byte{0x02, 0x00, 0x01}, 256},
+		{[]byte{0xfa}, -1},
+		{[]byte{0x80}, -123},
+		{[]byte{0xff, 0x84}, -124},
+		{[]byte{0xff, 0x00}, -256},
+		{[]byte{0xfe, 0xff, 0xfe}, -257},
+	}
+	for _, tt := range tests {
+		data := append([]byte{4, 8, 'i'}, tt.data...)
+		got, err := unmarshalRuby(data)
+		if err != nil {
+			t.Errorf("% x: %v", tt.data, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("% x = %v, want %d", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalRuby_Values(t *testing.T) {
+	// [nil, true, false, "héllo", :name, :name, "héllo" (link), 1.5, 2**64, {"a" => :b}]
+	b := newMarshalBuilder().array(10).null()
+	b.buf.WriteString("TF")
+	b.str("héllo").sym("name").sym("name").link(1)
+	b.buf.WriteString("f\x081.5")
+	b.buf.WriteString("l+\x0a\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00")
+	b.hash(1).str("a").sym("b")
+
+	got, err := unmarshalRuby(b.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, ok := got.([]any)
+	if !ok || len(items) != 10 {
+		t.Fatalf("got %#v, want a 10-item array", got)
+	}
+
+	want := []any{nil, true, false, "héllo", rubySymbol("name"), rubySymbol("name"), "héllo", 1.5}
+	if !reflect.DeepEqual(items[:8], want) {
+		t.Errorf("items = %#v, want %#v", items[:8], want)
+	}
+	if n, ok := items[8].(*big.Int); !ok || n.Cmp(new(big.Int).Lsh(big.NewInt(1), 64)) != 0 {
+		t.Errorf("bignum = %#v, want 2**64", items[8])
+	}
+	h, ok := items[9].(*rubyHash)
+	if !ok {
+		t.Fatalf("hash = %#v", items[9])
+	}
+	if v, ok := h.get("a"); !ok || v != rubySymbol("b") {
+		t.Errorf(`h["a"] = %#v, %v`, v, ok)
+	}
+}
+
+func TestUnmarshalRuby_Objects(t *testing.T) {
+	// [Gem::Version (marshal_dump), Gem::Dependency (ivars), Time (_dump)]
+	b := newMarshalBuilder().array(3)
+	b.userMarshal("Gem::Version").array(1).str("1.0.0")
+	b.object("Gem::Dependency", 2).sym("@name").str("rack").sym("@type").sym("runtime")
+	b.userDef("Time", []byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	got, err := unmarshalRuby(b.bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := got.([]any)
+
+	version, ok := items[0].(*rubyUserMarshal)
+	if !ok || version.Class != "Gem::Version" || !reflect.DeepEqual(version.Data, []any{"1.0.0"}) {
+		t.Errorf("version = %#v", items[0])
+	}
+	dep, ok := items[1].(*rubyObject)
+	if !ok || dep.Class != "Gem::Dependency" || dep.IVars["@name"] != "rack" || dep.IVars["@type"] != rubySymbol("runtime") {
+		t.Errorf("dependency = %#v", items[1])
+	}
+	tm, ok := items[2].(*rubyUserDef)
+	if !ok || tm.Class != "Time" || len(tm.Data) != 8 {
+		t.Errorf("time = %#v", items[2])
+	}
+}
+
+func TestUnmarshalRuby_SpecialFloats(t *testing.T) {
+	for text, check := range map[string]func(float64) bool{
+		"inf":  func(f float64) bool { return math.IsInf(f, 1) },
+		"-inf": func(f float64) bool { return math.IsInf(f, -1) },
+		"nan":  math.IsNaN,
+	} {
+		data := append([]byte{4, 8, 'f', byte(len(text) + 5)}, text...)
+		got, err := unmarshalRuby(data)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		if f, ok := got.(float64); !ok || !check(f) {
+			t.Errorf("%s = %v", text, got)
+		}
+	}
+}
+
+func TestUnmarshalRuby_Errors(t *testing.T) {
+	deep := newMarshalBuilder()
+	for range maxMarshalDepth + 1 {
+		deep.array(1)
+	}
+	deep.null()
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "reading header"},
+		{"wrong version", []byte{4, 7, '0'}, "unsupported version 4.7"},
+		{"truncated string", []byte{4, 8, '"', 0x0a, 'a'}, "unexpected EOF"},
+		{"bad object link", []byte{4, 8, '@', 0x06}, "bad object link"},
+		{"bad symbol link", []byte{4, 8, ';', 0x00}, "bad symbol link"},
+		{"unknown type", []byte{4, 8, '?'}, "unsupported type"},
+		{"negative length", []byte{4, 8, '"', 0xfa}, "bad length"},
+		{"huge bignum length", []byte{4, 8, 'l', '+', 0x04, 0xff, 0xff, 0xff, 0x7f}, "unexpected EOF"},
+		{"too deep", deep.bytes(), errMarshalDepth.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshalRuby(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := unmarshalRuby(deep.bytes()); !errors.Is(err, errMarshalDepth) {
+		t.Errorf("deep nesting err = %v, want errMarshalDepth", err)
+	}
+}
+
+func TestUnmarshalRuby_HugeLengthsDoNotAllocate(t *testing.T) {
+	// Lengths near 2**31 with no data behind them must fail without
+	// allocating the claimed size
+	for name, data := range map[string][]byte{
+		"bignum": {4, 8, 'l', '+', 0x04, 0xff, 0xff, 0xff, 0x7f},
+		"string": {4, 8, '"', 0x04, 0xff, 0xff, 0xff, 0x7f},
+	} {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		before := stats.TotalAlloc
+		if _, err := unmarshalRuby(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		runtime.ReadMemStats(&stats)
+		if grown := stats.TotalAlloc - before; grown > 1<<20 {
+			t.Errorf("%s: allocated %d bytes for a truncated value", name, grown)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxMarshalDependenciesSize caps one legacy dependency API response.
+const maxMarshalDependenciesSize = 32 << 20
+
+// MarshalDependencyClient reads the legacy dependency API,
+// /api/v1/dependencies?gems=a,b,c, which answers in Ruby Marshal format.
+// Older and private gem servers (Geminabox, older Artifactory) implement only
+// this endpoint, not the JSON API or the compact index.
+// Ruby equivalent: Bundler::Fetcher::Dependency
+type MarshalDependencyClient struct {
+	client *Client
+}
+
+// NewMarshalDependencyClient creates a legacy dependency API client that
+// shares the given client's server, credentials, and transport settings.
+func NewMarshalDependencyClient(client *Client) *MarshalDependencyClient {
+	return &MarshalDependencyClient{client: client}
+}
+
+// DependencyVersion is one published version of a gem and the runtime
+// dependencies it declares.
+type DependencyVersion struct {
+	Name         string
+	Version      string
+	Platform     string // "ruby" for the default platform
+	Dependencies []Dependency
+}
+
+// GetDependencyVersions returns every published version of the named gems,
+// asking for up to 50 gems per request. Gems the server does not know are
+// left out.
+func (mc *MarshalDependencyClient) GetDependencyVersions(names []string) ([]DependencyVersion, error) {
+	return mc.GetDependencyVersionsContext(context.Background(), names)
+}
+
+// GetDependencyVersionsContext is like GetDependencyVersions but aborts when ctx is canceled.
+func (mc *MarshalDependencyClient) GetDependencyVersionsContext(ctx context.Context, names []string) ([]DependencyVersion, error) {
+	entries, err := mc.fetchAll(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]DependencyVersion, 0, len(entries))
+	for _, e := range entries {
+		v := DependencyVersion{Name: e.Name, Version: e.Number, Platform: e.Platform}
+		for _, pair := range e.Dependencies {
+			v.Dependencies = append(v.Dependencies, Dependency{Name: pair[0], Requirements: pair[1]})
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+// GetDependencies returns the runtime dependencies of the latest version of
+// each named gem, chosen as GetDependenciesBulk does. Gems the server does
+// not know are absent from the map.
+func (mc *MarshalDependencyClient) GetDependencies(names []string) (map[string][]Dependency, error) {
+	return mc.GetDependenciesContext(context.Background(), names)
+}
+
+// GetDependenciesContext is like GetDependencies but aborts when ctx is canceled.
+func (mc *MarshalDependencyClient) GetDependenciesContext(ctx context.Context, names []string) (map[string][]Dependency, error) {
+	entries, err := mc.fetchAll(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	return mc.client.latestDependencies(entries), nil
+}
+
+// fetchAll fetches the named gems in chunks, one request at a time.
+func (mc *MarshalDependencyClient) fetchAll(ctx context.Context, names []string) ([]dependencyEntry, error) {
+	var entries []dependencyEntry
+	for _, chunk := range chunkNames(names, dependencyChunkSize) {
+		got, err := mc.fetchChunk(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, got...)
+	}
+	return entries, nil
+}
+
+// fetchChunk fetches and decodes one request's worth of gems.
+func (mc *MarshalDependencyClient) fetchChunk(ctx context.Context, names []string) ([]dependencyEntry, error) {
+	c := mc.client
+	gems := strings.Join(names, ",")
+	endpoint := c.baseURL + "/dependencies?" + url.Values{"gems": {gems}}.Encode()
+
+	req, err := c.newRequest(withGemName(ctx, gems), "GET", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", MIMEMarshal)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch dependencies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, strings.Join(names, ", "))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMarshalDependenciesSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dependencies: %w", err)
+	}
+	if len(data) > maxMarshalDependenciesSize {
+		return nil, fmt.Errorf("failed to read dependencies: response larger than %d bytes", maxMarshalDependenciesSize)
+	}
+
+	entries, err := parseMarshalDependencies(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode dependencies: %w", err)
+	}
+	return entries, nil
+}
+
+// parseMarshalDependencies decodes the legacy dependency API's response: an
+// Array of Hashes with :name, :number, :platform and :dependencies, the last
+// an Array of [name, requirements] pairs.
+func parseMarshalDependencies(data []byte) ([]dependencyEntry, error) {
+	v, err := unmarshalRuby(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("expected an Array of dependency Hashes")
+	}
+
+	entries := make([]dependencyEntry, 0, len(list))
+	for i, item := range list {
+		h, ok := item.(*rubyHash)
+		if !ok {
+			return nil, fmt.Errorf("entry %d is not a Hash", i)
+		}
+		name, _ := h.get("name")
+		number, _ := h.get("number")
+		platform, _ := h.get("platform")
+		entry := dependencyEntry{
+			Name:     specString(name),
+			Number:   specString(number),
+			Platform: specString(platform),
+		}
+		if entry.Name == "" || entry.Number == "" {
+			return nil, fmt.Errorf("entry %d has no name or number", i)
+		}
+		if entry.Platform == "" {
+			entry.Platform = string(PlatformRuby)
+		}
+
+		deps, _ := h.get("dependencies")
+		pairs, _ := deps.([]any)
+		for _, p := range pairs {
+			pair, ok := p.([]any)
+			if !ok || len(pair) != 2 {
+				return nil, fmt.Errorf("entry %d has a malformed dependency", i)
+			}
+			entry.Dependencies = append(entry.Dependencies, []string{specString(pair[0]), specString(pair[1])})
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,134 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// marshalDependencies builds a legacy dependency API response: one Hash per
+// [name, number, platform, deps...] row, where deps alternate name and
+// requirement.
+func marshalDependencies(rows ...[]string) []byte {
+	b := newMarshalBuilder().array(len(rows))
+	for _, row := range rows {
+		b.hash(4)
+		b.sym("name").str(row[0])
+		b.sym("number").str(row[1])
+		b.sym("platform").str(row[2])
+		deps := row[3:]
+		b.sym("dependencies").array(len(deps) / 2)
+		for i := 0; i < len(deps); i += 2 {
+			b.array(2).str(deps[i]).str(deps[i+1])
+		}
+	}
+	return b.bytes()
+}
+
+func newMarshalDependencyServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	rows := map[string][][]string{
+		"rails": {
+			{"rails", "7.0.0", "ruby", "actionpack", "= 7.0.0"},
+			{"rails", "7.1.0", "ruby", "actionpack", "= 7.1.0", "railties", "= 7.1.0"},
+			{"rails", "7.2.0.beta1", "ruby", "actionpack", "= 7.2.0.beta1"},
+		},
+		"nokogiri": {
+			{"nokogiri", "1.16.0", "ruby", "mini_portile2", "~> 2.8.2", "racc", "~> 1.4"},
+			{"nokogiri", "1.16.0", "java", "racc", "~> 1.4"},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/dependencies" || r.Header.Get("Accept") != MIMEMarshal {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var found [][]string
+		for _, name := range strings.Split(r.URL.Query().Get("gems"), ",") {
+			found = append(found, rows[name]...)
+		}
+		_, _ = w.Write(marshalDependencies(found...))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMarshalDependencyClient_GetDependencies(t *testing.T) {
+	server := newMarshalDependencyServer(t)
+	mc := NewMarshalDependencyClient(NewClientWithBaseURL(server.URL))
+
+	got, err := mc.GetDependencies([]string{"rails", "nokogiri", "unknown"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string][]Dependency{
+		"rails": {{Name: "actionpack", Requirements: "= 7.1.0"}, {Name: "railties", Requirements: "= 7.1.0"}},
+		"nokogiri": {
+			{Name: "mini_portile2", Requirements: "~> 2.8.2"},
+			{Name: "racc", Requirements: "~> 1.4"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestMarshalDependencyClient_GetDependencyVersions(t *testing.T) {
+	server := newMarshalDependencyServer(t)
+	mc := NewMarshalDependencyClient(NewClientWithBaseURL(server.URL))
+
+	got, err := mc.GetDependencyVersions([]string{"nokogiri"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []DependencyVersion{
+		{Name: "nokogiri", Version: "1.16.0", Platform: "ruby", Dependencies: []Dependency{
+			{Name: "mini_portile2", Requirements: "~> 2.8.2"},
+			{Name: "racc", Requirements: "~> 1.4"},
+		}},
+		{Name: "nokogiri", Version: "1.16.0", Platform: "java", Dependencies: []Dependency{
+			{Name: "racc", Requirements: "~> 1.4"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetDependencyVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMarshalDependencyClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("gems") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("<html>not marshal</html>"))
+	}))
+	defer server.Close()
+	mc := NewMarshalDependencyClient(NewClientWithBaseURL(server.URL))
+
+	if _, err := mc.GetDependencies([]string{"missing"}); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+	if _, err := mc.GetDependencies([]string{"rails"}); err == nil {
+		t.Error("expected an error for a non-Marshal response")
+	}
+}
+
+func TestParseMarshalDependencies_Malformed(t *testing.T) {
+	tests := map[string][]byte{
+		"not an array":   newMarshalBuilder().hash(0).bytes(),
+		"not a hash":     newMarshalBuilder().array(1).str("rails").bytes(),
+		"missing number": newMarshalBuilder().array(1).hash(1).sym("name").str("rails").bytes(),
+		"bad dependency": newMarshalBuilder().array(1).hash(3).
+			sym("name").str("rails").sym("number").str("7.0.0").
+			sym("dependencies").array(1).array(1).str("actionpack").bytes(),
+	}
+	for name, data := range tests {
+		if _, err := parseMarshalDependencies(data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
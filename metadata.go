@@ -0,0 +1,73 @@
+package rubygemsclient
+
+import "strings"
+
+// Metadata keys read from a gem's metadata.
+// Only these are treated as authoritative deprecation signals; free-form
+// text in the description or funding links is deliberately ignored.
+const (
+	MetadataDeprecated  = "deprecated"  // "true" or a deprecation message
+	MetadataReplacement = "replaced_by" // Name of the suggested successor gem
+	MetadataFundingURI  = "funding_uri" // Sponsorship/donation link
+
+	MetadataHomepageURI      = "homepage_uri"
+	MetadataSourceCodeURI    = "source_code_uri"
+	MetadataDocumentationURI = "documentation_uri"
+	MetadataChangelogURI     = "changelog_uri"
+	MetadataBugTrackerURI    = "bug_tracker_uri"
+	MetadataMFARequired      = "rubygems_mfa_required" // "true" when pushes need MFA
+)
+
+// DeprecationInfo describes a gem's declared deprecation.
+type DeprecationInfo struct {
+	Message     string // Deprecation message, empty if only flagged
+	Replacement string // Suggested replacement gem, empty if none declared
+}
+
+// deprecationFromMetadata returns the deprecation declared in metadata, or nil.
+// A gem is deprecated when the "deprecated" key is present and not "false".
+func deprecationFromMetadata(metadata map[string]string) *DeprecationInfo {
+	value, ok := metadata[MetadataDeprecated]
+	value = strings.TrimSpace(value)
+	if !ok || value == "" || strings.EqualFold(value, "false") {
+		return nil
+	}
+
+	info := &DeprecationInfo{
+		Replacement: strings.TrimSpace(metadata[MetadataReplacement]),
+	}
+	if !strings.EqualFold(value, "true") {
+		info.Message = value
+	}
+	return info
+}
+
+// MFARequired reports whether the gem's owners must use multi-factor
+// authentication to push or yank it. Like RubyGems, only the exact value
+// "true" counts.
+func (g *GemInfo) MFARequired() bool {
+	return g.Metadata[MetadataMFARequired] == "true"
+}
+
+// ChangelogURI returns the gem's declared changelog link, or "".
+func (g *GemInfo) ChangelogURI() string {
+	return g.Metadata[MetadataChangelogURI]
+}
+
+// BugTrackerURI returns the gem's declared issue tracker link, or "".
+func (g *GemInfo) BugTrackerURI() string {
+	return g.Metadata[MetadataBugTrackerURI]
+}
+
+// FundingLinks collects funding URIs across a set of gems, keyed by gem name.
+// Gems without a funding link, and nil entries, are skipped.
+func FundingLinks(gems []*GemInfo) map[string]string {
+	links := make(map[string]string)
+	for _, gem := range gems {
+		if gem == nil || gem.FundingURI == "" {
+			continue
+		}
+		links[gem.Name] = gem.FundingURI
+	}
+	return links
+}
@@ -0,0 +1,216 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeprecationFromMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     *DeprecationInfo
+	}{
+		{
+			name:     "no metadata",
+			metadata: nil,
+			want:     nil,
+		},
+		{
+			name:     "explicitly not deprecated",
+			metadata: map[string]string{"deprecated": "false"},
+			want:     nil,
+		},
+		{
+			name:     "flag only",
+			metadata: map[string]string{"deprecated": "true"},
+			want:     &DeprecationInfo{},
+		},
+		{
+			name: "message and replacement",
+			metadata: map[string]string{
+				"deprecated":  "No longer maintained, use new-gem",
+				"replaced_by": "new-gem",
+			},
+			want: &DeprecationInfo{Message: "No longer maintained, use new-gem", Replacement: "new-gem"},
+		},
+		{
+			name:     "replacement alone is not a deprecation",
+			metadata: map[string]string{"replaced_by": "new-gem"},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deprecationFromMetadata(tt.metadata)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("deprecationFromMetadata() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && *got != *tt.want {
+				t.Errorf("deprecationFromMetadata() = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGemInfo_Deprecation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"old-gem","metadata":{"deprecated":"true","replaced_by":"new-gem"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	info, err := client.GetGemInfo("old-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Deprecation == nil {
+		t.Fatal("Expected deprecation info")
+	}
+	if info.Deprecation.Replacement != "new-gem" {
+		t.Errorf("Expected replacement new-gem, got %q", info.Deprecation.Replacement)
+	}
+}
+
+func TestGetGemInfo_FundingURI(t *testing.T) {
+	bodies := map[string]string{
+		"/gems/top-level.json": `{"name":"top-level","funding_uri":"https://github.com/sponsors/a"}`,
+		"/gems/in-meta.json":   `{"name":"in-meta","metadata":{"funding_uri":"https://opencollective.com/b"}}`,
+		"/gems/none.json":      `{"name":"none"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	var gems []*GemInfo
+	for _, name := range []string{"top-level", "in-meta", "none"} {
+		info, err := client.GetGemInfo(name, "1.0.0")
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", name, err)
+		}
+		gems = append(gems, info)
+	}
+	gems = append(gems, nil)
+
+	links := FundingLinks(gems)
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 funding links, got %v", links)
+	}
+	if links["top-level"] != "https://github.com/sponsors/a" {
+		t.Errorf("Unexpected top-level link %q", links["top-level"])
+	}
+	if links["in-meta"] != "https://opencollective.com/b" {
+		t.Errorf("Unexpected metadata link %q", links["in-meta"])
+	}
+}
+
+func TestGetGemInfo_LicensesAndLinks(t *testing.T) {
+	bodies := map[string]string{
+		"/gems/top-level.json": `{"name":"top-level","licenses":["MIT","Apache-2.0"],
+			"homepage_uri":"https://rack.github.io","source_code_uri":"https://github.com/rack/rack",
+			"documentation_uri":"https://rubydoc.info/gems/rack"}`,
+		"/gems/in-meta.json": `{"name":"in-meta","licenses":null,"source_code_uri":null,
+			"metadata":{"source_code_uri":"https://github.com/a/b","documentation_uri":"https://a.dev/docs"}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	info, err := client.GetGemInfo("top-level", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !slices.Equal(info.Licenses, []string{"MIT", "Apache-2.0"}) {
+		t.Errorf("Unexpected licenses %v", info.Licenses)
+	}
+	if info.HomepageURI != "https://rack.github.io" ||
+		info.SourceCodeURI != "https://github.com/rack/rack" ||
+		info.DocumentationURI != "https://rubydoc.info/gems/rack" {
+		t.Errorf("Unexpected links %+v", info)
+	}
+
+	info, err = client.GetGemInfo("in-meta", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(info.Licenses) != 0 {
+		t.Errorf("Expected no licenses, got %v", info.Licenses)
+	}
+	if info.SourceCodeURI != "https://github.com/a/b" || info.DocumentationURI != "https://a.dev/docs" {
+		t.Errorf("Expected links from metadata, got %+v", info)
+	}
+	if info.HomepageURI != "" {
+		t.Errorf("Expected no homepage, got %q", info.HomepageURI)
+	}
+}
+
+func TestGemInfo_MetadataHelpers(t *testing.T) {
+	info := &GemInfo{Metadata: map[string]string{
+		"rubygems_mfa_required": "true",
+		"changelog_uri":         "https://github.com/rack/rack/blob/main/CHANGELOG.md",
+		"bug_tracker_uri":       "https://github.com/rack/rack/issues",
+	}}
+	if !info.MFARequired() {
+		t.Error("Expected MFA to be required")
+	}
+	if got := info.ChangelogURI(); got != "https://github.com/rack/rack/blob/main/CHANGELOG.md" {
+		t.Errorf("Unexpected changelog %q", got)
+	}
+	if got := info.BugTrackerURI(); got != "https://github.com/rack/rack/issues" {
+		t.Errorf("Unexpected bug tracker %q", got)
+	}
+
+	for _, value := range []string{"", "false", "TRUE", "yes"} {
+		info := &GemInfo{Metadata: map[string]string{"rubygems_mfa_required": value}}
+		if info.MFARequired() {
+			t.Errorf("MFARequired() with %q = true, want false", value)
+		}
+	}
+	if (&GemInfo{}).MFARequired() {
+		t.Error("Expected no MFA requirement without metadata")
+	}
+}
+
+func TestGetGemInfo_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name":"rack","metadata":{"rubygems_mfa_required":"true","changelog_uri":"https://example.com/CHANGELOG.md"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	info, err := client.GetGemInfo("rack", "3.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !info.MFARequired() || info.ChangelogURI() != "https://example.com/CHANGELOG.md" {
+		t.Errorf("Unexpected metadata %v", info.Metadata)
+	}
+}
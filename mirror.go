@@ -0,0 +1,72 @@
+package rubygemsclient
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// mirrorAllKey configures a mirror for every source.
+const mirrorAllKey = mirrorKeyPrefix + "ALL"
+
+// MirrorFor returns the mirror configured for a gem source URL, or "" if
+// there is none. Like Bundler, it reads keys such as
+//
+//	BUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/: "https://gems.internal.example.com"
+//
+// from local config, the environment, and global config, in that order, and
+// falls back to a BUNDLE_MIRROR__ALL mirror.
+func MirrorFor(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	key := mirrorKeyPrefix + strings.TrimPrefix(uriToEnvKey(u.Scheme, u.Host, segments), "BUNDLE_")
+
+	for _, k := range []string{key, mirrorAllKey} {
+		if mirror := lookupMirror(k); mirror != "" {
+			return strings.TrimSuffix(mirror, "/")
+		}
+	}
+	return ""
+}
+
+// lookupMirror checks one mirror key in local config, env, then global config.
+func lookupMirror(key string) string {
+	if mirror := GetLocalBundleConfig().mirrorForKey(key); mirror != "" {
+		return mirror
+	}
+	if mirror := strings.TrimSpace(os.Getenv(key)); mirror != "" {
+		return mirror
+	}
+	return GetGlobalBundleConfig().mirrorForKey(key)
+}
+
+// WithMirror sends every request to mirrorURL instead of the client's base
+// URL, keeping the paths. NewClient and NewClientWithBaseURL already apply
+// the mirror from MirrorFor; use WithMirror to pick one explicitly, or
+// WithMirror("") to ignore configured mirrors.
+//
+// Requests to the mirror carry the original source's credentials, so a
+// mirror on another host receives them, unless CredentialsForURL finds
+// credentials for the mirror itself, which are then sent instead.
+func WithMirror(mirrorURL string) ClientOption {
+	return func(c *Client) {
+		c.mirror = strings.TrimSuffix(mirrorURL, "/")
+	}
+}
+
+// mirrorURL rewrites a request URL under the client's server root to the
+// mirror. URLs elsewhere (and all URLs without a mirror) are returned as-is.
+func (c *Client) mirrorURL(rawURL string) string {
+	if c.mirror == "" {
+		return rawURL
+	}
+	root := c.serverRoot()
+	if rest, ok := strings.CutPrefix(rawURL, root); ok && (rest == "" || rest[0] == '/' || rest[0] == '?') {
+		return c.mirror + rest
+	}
+	return rawURL
+}
@@ -0,0 +1,134 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useBundleConfigs points local and global config at fresh files with the
+// given contents ("" for none) and resets the config cache around the test.
+func useBundleConfigs(t *testing.T, local, global string) {
+	t.Helper()
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+
+	write := func(dir, content string) {
+		if content == "" {
+			return
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	appConfig, userHome := t.TempDir(), t.TempDir()
+	write(appConfig, local)
+	write(filepath.Join(userHome, ".bundle"), global)
+	t.Setenv("BUNDLE_APP_CONFIG", appConfig)
+	t.Setenv("BUNDLE_USER_HOME", userHome)
+}
+
+func TestMirrorFor(t *testing.T) {
+	useBundleConfigs(t,
+		"---\nBUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/: \"https://local-mirror.example.com/\"\n",
+		"---\nBUNDLE_MIRROR__HTTPS://GEMS__EXAMPLE__COM/PRIVATE/: \"https://private-mirror.example.com\"\n"+
+			"BUNDLE_MIRROR__ALL: \"https://catch-all.example.com\"\n")
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"https://rubygems.org", "https://local-mirror.example.com"},
+		{"https://rubygems.org/", "https://local-mirror.example.com"},
+		{"https://gems.example.com/private", "https://private-mirror.example.com"},
+		{"https://other.example.com", "https://catch-all.example.com"},
+		{"not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := MirrorFor(tt.source); got != tt.want {
+			t.Errorf("MirrorFor(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+
+	// Mirror entries are not credentials
+	if creds := LoadBundleConfig().credentialsForKey("BUNDLE_MIRROR__ALL"); creds != nil {
+		t.Errorf("mirror parsed as credentials: %+v", creds)
+	}
+}
+
+func TestMirrorFor_EnvPrecedence(t *testing.T) {
+	useBundleConfigs(t, "", "---\nBUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/: \"https://global.example.com\"\n")
+	t.Setenv("BUNDLE_MIRROR__HTTPS://RUBYGEMS__ORG/", "https://env.example.com")
+
+	if got := MirrorFor("https://rubygems.org"); got != "https://env.example.com" {
+		t.Errorf("expected env mirror to beat global config, got %q", got)
+	}
+}
+
+func TestClient_RoutesToMirror(t *testing.T) {
+	var gotAuth, gotPath string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotPath = r.Header.Get("Authorization"), r.URL.Path
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "rack"})
+	}))
+	defer mirror.Close()
+
+	useBundleConfigs(t, "---\nBUNDLE_MIRROR__HTTPS://GEMS__EXAMPLE__COM/: \""+mirror.URL+"/cache\"\n", "")
+
+	client := NewClientWithBaseURL("https://gems.example.com",
+		WithCredentials(&Credentials{Token: "origin-token"}),
+		WithRequiredCredentialHosts([]string{"gems.example.com"}))
+
+	if _, err := client.GetGemInfo("rack", "3.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotPath != "/cache/api/v1/gems/rack.json" {
+		t.Errorf("mirror saw path %q", gotPath)
+	}
+	if gotAuth != "Bearer origin-token" {
+		t.Errorf("expected the original source's credentials, got %q", gotAuth)
+	}
+
+	// Credential requirements still apply to the original host
+	anonymous := NewClientWithBaseURL("https://gems.example.com",
+		WithRequiredCredentialHosts([]string{"gems.example.com"}))
+	var missing *MissingCredentialsError
+	if _, err := anonymous.GetGemInfo("rack", "3.0.0"); !errors.As(err, &missing) {
+		t.Errorf("expected MissingCredentialsError, got %v", err)
+	}
+
+	// WithMirror("") opts out of configured mirrors
+	direct := NewClientWithBaseURL("https://gems.example.com", WithMirror(""))
+	if direct.mirror != "" {
+		t.Errorf("expected no mirror, got %q", direct.mirror)
+	}
+}
+
+func TestClient_MirrorWithOwnCredentials(t *testing.T) {
+	var gotAuth string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "rack"})
+	}))
+	defer mirror.Close()
+
+	useBundleConfigs(t, "---\nBUNDLE_MIRROR__HTTPS://GEMS__EXAMPLE__COM/: \""+mirror.URL+"\"\n", "")
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv(hostToEnvKey("127.0.0.1"), "any:mirror-token")
+
+	client := NewClientWithBaseURL("https://gems.example.com", WithCredentials(&Credentials{Token: "origin-token"}))
+	if _, err := client.GetGemInfo("rack", "3.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer mirror-token" {
+		t.Errorf("expected the mirror's own credentials, got %q", gotAuth)
+	}
+}
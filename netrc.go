@@ -0,0 +1,135 @@
+package rubygemsclient
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// netrcMachine is one machine entry from a .netrc file.
+type netrcMachine struct {
+	login    string
+	password string
+}
+
+// tokenPlaceholders are netrc logins or passwords that only mark the other
+// field as a token, as used by GitHub and other hosts.
+var tokenPlaceholders = map[string]bool{
+	"x-oauth-basic":  true,
+	"x-access-token": true,
+	"oauth2":         true,
+	tokenUsername:    true,
+}
+
+// CredentialsFromNetrc resolves credentials for a host from $NETRC or
+// ~/.netrc. A login such as "x-oauth-basic" or "x-access-token" marks the
+// password as a token (and vice versa); other entries are basic auth.
+// "default" entries are ignored so credentials never leak to unrelated hosts.
+// Returns nil if no machine matches.
+//
+// Note: Prefer using CredentialsFor() which checks netrc last.
+func CredentialsFromNetrc(host string) *Credentials {
+	m, ok := loadNetrc()[normalizeHost(host)]
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case tokenPlaceholders[m.login] && m.password != "":
+		return &Credentials{Token: m.password}
+	case tokenPlaceholders[m.password] && m.login != "":
+		return &Credentials{Token: m.login}
+	case m.login == "" && m.password != "":
+		return &Credentials{Token: m.password}
+	case m.login != "":
+		return &Credentials{Username: m.login, Password: m.password}
+	}
+	return nil
+}
+
+// loadNetrc returns the parsed netrc machines, reading the file on first use.
+// The map is never modified after loading.
+func loadNetrc() map[string]netrcMachine {
+	configs.mu.Lock()
+	defer configs.mu.Unlock()
+	if !configs.netrcLoaded {
+		if data, err := os.ReadFile(netrcPath()); err == nil {
+			configs.netrcMachines = parseNetrc(string(data))
+		}
+		configs.netrcLoaded = true
+	}
+	return configs.netrcMachines
+}
+
+// netrcPath returns $NETRC or ~/.netrc.
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".netrc")
+	}
+	return ""
+}
+
+// parseNetrc parses machine entries keyed by lowercase host. The first entry
+// for a host wins, as in curl and Ruby's net-netrc.
+func parseNetrc(data string) map[string]netrcMachine {
+	machines := make(map[string]netrcMachine)
+
+	var host string
+	var current netrcMachine
+	inMachine := false
+	flush := func() {
+		if inMachine {
+			if _, seen := machines[host]; !seen {
+				machines[host] = current
+			}
+		}
+		inMachine = false
+	}
+
+	lines := strings.Split(data, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for j := 0; j < len(fields); j++ {
+			switch fields[j] {
+			case "machine":
+				flush()
+				if j+1 < len(fields) {
+					j++
+					host, current, inMachine = normalizeHost(fields[j]), netrcMachine{}, true
+				}
+			case "default":
+				flush()
+			case "login":
+				if j+1 < len(fields) {
+					j++
+					current.login = fields[j]
+				}
+			case "password":
+				if j+1 < len(fields) {
+					j++
+					current.password = fields[j]
+				}
+			case "account":
+				j++ // Value unused
+			case "macdef":
+				// A macro runs until the next blank line
+				flush()
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	flush()
+
+	return machines
+}
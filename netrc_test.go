@@ -0,0 +1,95 @@
+package rubygemsclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const netrcFixture = `# Private gem hosts
+machine gems.example.com
+  login alice
+  password s3cret
+
+machine rubygems.pkg.github.com login x-oauth-basic password ghp_token
+machine token-first.example.com login ghp_other password x-oauth-basic
+machine bare.example.com password only_token
+
+macdef init
+machine macro.example.com login fake password fake
+
+machine gems.example.com login duplicate password ignored
+default login anonymous password guest
+`
+
+// useNetrc points $NETRC at a file with content and resets cached config.
+func useNetrc(t *testing.T, content string) {
+	t.Helper()
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+
+	path := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NETRC", path)
+}
+
+func TestCredentialsFromNetrc(t *testing.T) {
+	useNetrc(t, netrcFixture)
+
+	tests := []struct {
+		host string
+		want *Credentials
+	}{
+		{"gems.example.com", &Credentials{Username: "alice", Password: "s3cret"}},
+		{"GEMS.example.com:443", &Credentials{Username: "alice", Password: "s3cret"}},
+		{"rubygems.pkg.github.com", &Credentials{Token: "ghp_token"}},
+		{"token-first.example.com", &Credentials{Token: "ghp_other"}},
+		{"bare.example.com", &Credentials{Token: "only_token"}},
+		{"macro.example.com", nil}, // Inside a macro definition
+		{"unknown.example.com", nil},
+	}
+	for _, tt := range tests {
+		got := CredentialsFromNetrc(tt.host)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("CredentialsFromNetrc(%q) = %+v, want %+v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestCredentialsFor_NetrcIsLowestPriority(t *testing.T) {
+	useNetrc(t, "machine netrc.test login any password netrc_token\n")
+	t.Chdir(t.TempDir())
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	if creds := CredentialsFor("netrc.test"); creds == nil || creds.GetToken() != "netrc_token" {
+		t.Errorf("expected netrc fallback, got %+v", creds)
+	}
+
+	t.Setenv("BUNDLE_NETRC__TEST", "any:env_token")
+	if creds := CredentialsFor("netrc.test"); creds == nil || creds.GetToken() != "env_token" {
+		t.Errorf("expected env to beat netrc, got %+v", creds)
+	}
+}
+
+func TestCredentialsForMatching_NetrcIsExactOnly(t *testing.T) {
+	useNetrc(t, "machine netrc.test login any password netrc_token\n")
+	t.Chdir(t.TempDir())
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	if creds := CredentialsForMatching("netrc.test", HostMatchSuffix); creds == nil || creds.GetToken() != "netrc_token" {
+		t.Errorf("expected the exact netrc machine, got %+v", creds)
+	}
+
+	// The parent's netrc machine does not cover a subdomain
+	if creds := CredentialsForMatching("api.netrc.test", HostMatchSuffix); creds != nil {
+		t.Errorf("expected no credentials for a subdomain, got %+v", creds)
+	}
+
+	// A parent from the bundle config does
+	t.Setenv("BUNDLE_NETRC__TEST", "any:env_token")
+	if creds := CredentialsForMatching("api.netrc.test", HostMatchSuffix); creds == nil || creds.GetToken() != "env_token" {
+		t.Errorf("expected the parent's env credentials, got %+v", creds)
+	}
+}
@@ -0,0 +1,30 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Owner is an account that can push new versions of a gem.
+type Owner struct {
+	Handle string `json:"handle"`
+	Email  string `json:"email"` // Empty unless the owner made it public
+}
+
+// GetGemOwners returns the owners of a gem from /gems/<gem>/owners.json.
+// The client's credentials are sent as usual; some servers require them
+// for this endpoint, in which case errors.Is(err, ErrUnauthorized) reports it.
+func (c *Client) GetGemOwners(name string) ([]Owner, error) {
+	return c.GetGemOwnersContext(context.Background(), name)
+}
+
+// GetGemOwnersContext is like GetGemOwners but aborts when ctx is canceled.
+func (c *Client) GetGemOwnersContext(ctx context.Context, name string) ([]Owner, error) {
+	var owners []Owner
+	endpoint := fmt.Sprintf("%s/gems/%s/owners.json", c.baseURL, url.PathEscape(name))
+	if err := c.getJSON(ctx, endpoint, name, "gem owners", &owners); err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
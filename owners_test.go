@@ -0,0 +1,53 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetGemOwners(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/gems/rack/owners.json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+		_, _ = w.Write([]byte(`[
+			{"id": 1, "handle": "alice", "email": "alice@example.com"},
+			{"id": 2, "handle": "bob", "email": null}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "secret"}))
+
+	owners, err := client.GetGemOwners("rack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Owner{{Handle: "alice", Email: "alice@example.com"}, {Handle: "bob"}}
+	if len(owners) != len(want) {
+		t.Fatalf("got %d owners, want %d", len(owners), len(want))
+	}
+	for i := range want {
+		if owners[i] != want[i] {
+			t.Errorf("owners[%d] = %+v, want %+v", i, owners[i], want[i])
+		}
+	}
+}
+
+func TestGetGemOwners_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	if _, err := client.GetGemOwners("private-gem"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
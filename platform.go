@@ -0,0 +1,62 @@
+package rubygemsclient
+
+import "strings"
+
+// Platform is a RubyGems platform such as "ruby", "java", "x86_64-linux",
+// or "arm64-darwin".
+type Platform string
+
+// PlatformRuby is the platform of pure-Ruby gems, which install anywhere.
+const PlatformRuby Platform = "ruby"
+
+// IsRuby reports whether p is the default ruby platform. The empty string
+// counts as ruby, matching how the compact index omits it.
+func (p Platform) IsRuby() bool {
+	return p == "" || p == PlatformRuby
+}
+
+// Supports reports whether a gem built for gemPlatform installs on p: either
+// it is a pure-Ruby gem or it was built for exactly this platform.
+func (p Platform) Supports(gemPlatform string) bool {
+	return Platform(gemPlatform).IsRuby() || strings.EqualFold(gemPlatform, string(p))
+}
+
+// WithPlatform targets a platform: GetGemVersions only returns versions
+// installable on it, and GetGemInfo and CompactIndexClient report the
+// dependencies of its platform build when one exists.
+func WithPlatform(p Platform) ClientOption {
+	return func(c *Client) {
+		c.platform = p
+	}
+}
+
+// versionsForPlatform keeps the versions installable on p, collapsing
+// multiple builds of the same number into its first entry.
+func versionsForPlatform(versions []VersionInfo, p Platform) []VersionInfo {
+	seen := make(map[string]bool, len(versions))
+	var kept []VersionInfo
+	for _, v := range versions {
+		if seen[v.Number] || !p.Supports(v.Platform) {
+			continue
+		}
+		seen[v.Number] = true
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// platformRank orders builds of one version for target: its own platform
+// build, then the ruby build, then (only with no target) anything else.
+// Zero means the build does not install on target.
+func platformRank(target Platform, gemPlatform string) int {
+	switch {
+	case !target.IsRuby() && strings.EqualFold(gemPlatform, string(target)):
+		return 3
+	case Platform(gemPlatform).IsRuby():
+		return 2
+	case target == "":
+		return 1
+	default:
+		return 0
+	}
+}
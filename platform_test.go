@@ -0,0 +1,134 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlatform_Supports(t *testing.T) {
+	linux := Platform("x86_64-linux")
+
+	tests := []struct {
+		target Platform
+		gem    string
+		want   bool
+	}{
+		{linux, "ruby", true},
+		{linux, "", true},
+		{linux, "x86_64-linux", true},
+		{linux, "X86_64-Linux", true},
+		{linux, "arm64-darwin", false},
+		{PlatformRuby, "ruby", true},
+		{PlatformRuby, "java", false},
+	}
+	for _, tt := range tests {
+		if got := tt.target.Supports(tt.gem); got != tt.want {
+			t.Errorf("%q.Supports(%q) = %v, want %v", tt.target, tt.gem, got, tt.want)
+		}
+	}
+}
+
+const platformVersionsJSON = `[
+	{"number": "1.16.0", "platform": "arm64-darwin"},
+	{"number": "1.16.0", "platform": "x86_64-linux"},
+	{"number": "1.16.0", "platform": "ruby"},
+	{"number": "1.15.0", "platform": "java"},
+	{"number": "1.14.0", "platform": "ruby"}
+]`
+
+func TestGetGemVersions_Platform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(platformVersionsJSON))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts []ClientOption
+		want []string
+	}{
+		{"no platform", nil, []string{"1.16.0", "1.16.0", "1.16.0", "1.15.0", "1.14.0"}},
+		{"linux", []ClientOption{WithPlatform("x86_64-linux")}, []string{"1.16.0", "1.14.0"}},
+		{"java", []ClientOption{WithPlatform("java")}, []string{"1.16.0", "1.15.0", "1.14.0"}},
+		{"ruby", []ClientOption{WithPlatform(PlatformRuby)}, []string{"1.16.0", "1.14.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithBaseURL(server.URL, tt.opts...)
+			versions, err := client.GetGemVersions("nokogiri")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("got %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGemInfo_Platform(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v2/rubygems/nokogiri/versions/1.16.0.json" && r.URL.Query().Get("platform") == "x86_64-linux":
+			_, _ = w.Write([]byte(`{"name": "nokogiri", "version": "1.16.0", "platform": "x86_64-linux",
+				"dependencies": {"runtime": [{"name": "racc", "requirements": "~> 1.4"}]}}`))
+		case r.URL.Path == "/api/v1/gems/nokogiri.json":
+			_, _ = w.Write([]byte(`{"name": "nokogiri", "version": "1.16.0", "platform": "ruby",
+				"dependencies": {"runtime": [{"name": "mini_portile2", "requirements": "~> 2.8"},
+				                             {"name": "racc", "requirements": "~> 1.4"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	linux := NewClientWithBaseURL(server.URL, WithPlatform("x86_64-linux"))
+	info, err := linux.GetGemInfo("nokogiri", "1.16.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Platform != "x86_64-linux" || len(info.Dependencies.Runtime) != 1 {
+		t.Errorf("expected linux build deps, got %s %v", info.Platform, info.Dependencies.Runtime)
+	}
+
+	// No darwin build on the server: fall back to the ruby gem
+	darwin := NewClientWithBaseURL(server.URL, WithPlatform("arm64-darwin"))
+	info, err = darwin.GetGemInfo("nokogiri", "1.16.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Platform != "ruby" || len(info.Dependencies.Runtime) != 2 {
+		t.Errorf("expected ruby gem deps, got %s %v", info.Platform, info.Dependencies.Runtime)
+	}
+}
+
+func TestCompactIndexClient_Platform(t *testing.T) {
+	server := newInfoServer(t)
+
+	tests := []struct {
+		platform Platform
+		version  string
+		want     string
+	}{
+		{"x86_64-linux", "1.15.0", "x86_64-linux"},
+		{"java", "1.15.0", "java"},
+		{"arm64-darwin", "1.15.0", "ruby"},
+		{"arm64-darwin", "1.16.0", "arm64-darwin"},
+		{PlatformRuby, "1.16.0", "ruby"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.platform)+" "+tt.version, func(t *testing.T) {
+			ci := NewCompactIndexClient(NewClientWithBaseURL(server.URL, WithPlatform(tt.platform)))
+			info, err := ci.GetGemInfoForVersion("nokogiri", tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.EqualFold(info.Platform, tt.want) {
+				t.Errorf("platform = %s, want %s", info.Platform, tt.want)
+			}
+		})
+	}
+}
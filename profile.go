@@ -0,0 +1,41 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// profileResponse is the part of /profile/me.json WhoAmI reads.
+type profileResponse struct {
+	Handle string `json:"handle"`
+}
+
+// WhoAmI returns the handle of the account the client's credentials belong
+// to, from the authenticated /profile/me.json endpoint, as a cheap check that
+// configured credentials work. Rejected or missing credentials are reported
+// as an *APIError matching ErrUnauthorized, and a server without the
+// endpoint as ErrNotSupported. rubygems.org documents username/password
+// credentials for this endpoint.
+func (c *Client) WhoAmI() (string, error) {
+	return c.WhoAmIContext(context.Background())
+}
+
+// WhoAmIContext is like WhoAmI but aborts when ctx is canceled.
+func (c *Client) WhoAmIContext(ctx context.Context) (string, error) {
+	var profile profileResponse
+	// No gem name: a 404 here is a missing endpoint, not a missing gem
+	err := c.getJSON(ctx, c.baseURL+"/profile/me.json", "", "profile", &profile)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("profile: %w", ErrNotSupported)
+	}
+	if err != nil {
+		return "", err
+	}
+	if profile.Handle == "" {
+		return "", errors.New("profile response has no handle")
+	}
+	return profile.Handle, nil
+}
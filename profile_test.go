@@ -0,0 +1,68 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhoAmI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/profile/me.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "alice" || pass != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"handle":"alice","email":"alice@example.com"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Username: "alice", Password: "hunter2"}))
+	handle, err := client.WhoAmI()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if handle != "alice" {
+		t.Errorf("WhoAmI() = %q, want alice", handle)
+	}
+
+	for name, client := range map[string]*Client{
+		"no credentials": NewClientWithBaseURL(server.URL),
+		"wrong password": NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Username: "alice", Password: "nope"})),
+	} {
+		if _, err := client.WhoAmI(); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("%s: expected ErrUnauthorized, got %v", name, err)
+		}
+	}
+}
+
+func TestWhoAmI_NoHandle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewClientWithBaseURL(server.URL).WhoAmI(); err == nil {
+		t.Error("expected an error for a profile without a handle")
+	}
+}
+
+func TestWhoAmI_NotAGemLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tracer := &fakeTracer{}
+	_, err := NewClientWithBaseURL(server.URL, WithTracer(tracer)).WhoAmI()
+	if !errors.Is(err, ErrNotSupported) || errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrNotSupported rather than ErrGemNotFound, got %v", err)
+	}
+	if name, ok := tracer.spans[0].attrs[SpanAttrGemName]; ok {
+		t.Errorf("expected no gem name on the span, got %v", name)
+	}
+}
@@ -0,0 +1,107 @@
+package rubygemsclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GemHostError is returned when the gem host rejects a push, yank or unyank.
+// Message is the server's explanation, e.g. "Repushing of gem versions is
+// not allowed." errors.Is(err, ErrUnauthorized) reports a rejected API key.
+type GemHostError struct {
+	Op         string // "push", "yank" or "unyank"
+	StatusCode int
+	Message    string
+}
+
+func (e *GemHostError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("gem %s failed with status %d", e.Op, e.StatusCode)
+	}
+	return fmt.Sprintf("gem %s failed with status %d: %s", e.Op, e.StatusCode, e.Message)
+}
+
+// Is reports whether the error matches one of the sentinels ErrUnauthorized,
+// ErrNotOwner or ErrAlreadyYanked.
+func (e *GemHostError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotOwner:
+		return e.StatusCode == http.StatusForbidden
+	case ErrAlreadyYanked:
+		return e.Op == "yank" && alreadyYanked(e.Message)
+	}
+	return false
+}
+
+// PushGem uploads a built .gem file to the client's server, like gem push
+// --host. The file is read fully before sending, as the gem CLI does. The
+// API key is the client's token if it has one, otherwise the key APIKeyFor
+// resolves for the server; without either a *MissingCredentialsError is
+// returned. Pushes always go to the server itself, never a mirror, and are
+// not retried. A rejected push returns a *GemHostError.
+func (c *Client) PushGem(r io.Reader) error {
+	return c.PushGemContext(context.Background(), r)
+}
+
+// PushGemContext is like PushGem but aborts when ctx is canceled.
+func (c *Client) PushGemContext(ctx context.Context, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read gem: %w", err)
+	}
+	return c.gemHostRequest(ctx, "push", http.MethodPost, c.baseURL+"/gems", "application/octet-stream", data)
+}
+
+// apiKey returns the key sent to the gem host's endpoints for changing gems:
+// the token for requests made with ctx, or else the key the gem CLI would
+// use for the server.
+func (c *Client) apiKey(ctx context.Context) string {
+	if token := c.requestCredentials(ctx).GetToken(); token != "" {
+		return token
+	}
+	return APIKeyFor(c.serverRoot())
+}
+
+// gemHostRequest sends a request that changes gems on the server, returning
+// a *GemHostError with the server's message if it is rejected. Like the gem
+// CLI, it authenticates with the bare API key in the Authorization header.
+func (c *Client) gemHostRequest(ctx context.Context, op, method, rawURL, contentType string, body []byte) error {
+	key := ""
+	if !c.isAnonymous(ctx) {
+		key = c.apiKey(ctx)
+	}
+	if key == "" {
+		return &MissingCredentialsError{Host: apiKeyHost(rawURL)}
+	}
+
+	req, err := c.buildRequest(ctx, method, rawURL, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", key)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := c.send(req)
+	if err != nil {
+		return fmt.Errorf("gem %s failed: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		discardBody(resp)
+		return nil
+	}
+	text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &GemHostError{
+		Op:         op,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(redactSecrets(string(text), requestSecrets(resp.Request))),
+	}
+}
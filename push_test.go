@@ -0,0 +1,142 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPushGem(t *testing.T) {
+	var gotBody, gotAuth, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/gems" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth, gotType = string(body), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		_, _ = w.Write([]byte("Successfully registered gem: rack (3.0.0)"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "rubygems_key"}))
+	if err := client.PushGem(strings.NewReader("gem bytes")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBody != "gem bytes" {
+		t.Errorf("body = %q", gotBody)
+	}
+	if gotAuth != "rubygems_key" {
+		t.Errorf("Authorization = %q, want the bare API key", gotAuth)
+	}
+	if gotType != "application/octet-stream" {
+		t.Errorf("Content-Type = %q", gotType)
+	}
+}
+
+func TestPushGem_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "rubygems_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("Access Denied for " + r.Header.Get("Authorization")))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("Repushing of gem versions is not allowed.\n"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "rubygems_key"}))
+	err := client.PushGem(strings.NewReader("gem bytes"))
+	var hostErr *GemHostError
+	if !errors.As(err, &hostErr) {
+		t.Fatalf("expected *GemHostError, got %v", err)
+	}
+	if hostErr.Op != "push" || hostErr.StatusCode != http.StatusConflict || hostErr.Message != "Repushing of gem versions is not allowed." {
+		t.Errorf("unexpected error %+v", hostErr)
+	}
+
+	client = NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "wrong_key"}))
+	err = client.PushGem(strings.NewReader("gem bytes"))
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if strings.Contains(err.Error(), "wrong_key") {
+		t.Errorf("error leaked the API key: %v", err)
+	}
+}
+
+func TestPushGem_APIKeyFromEnvironment(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	t.Setenv("GEM_HOST_API_KEY", "env_key")
+	if err := NewClientWithBaseURL(server.URL).PushGem(strings.NewReader("gem")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotAuth != "env_key" {
+		t.Errorf("Authorization = %q, want env_key", gotAuth)
+	}
+}
+
+func TestPushGem_MissingAPIKey(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GEM_HOST_API_KEY", "")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	err := NewClientWithBaseURL(server.URL).PushGem(strings.NewReader("gem"))
+	var missing *MissingCredentialsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected *MissingCredentialsError, got %v", err)
+	}
+	if missing.Host != "127.0.0.1" {
+		t.Errorf("Host = %q, want 127.0.0.1", missing.Host)
+	}
+	if requests.Load() != 0 {
+		t.Error("expected no request without an API key")
+	}
+}
+
+func TestPushGem_NotRetriedOrMirrored(t *testing.T) {
+	var sourceRequests, mirrorRequests atomic.Int32
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sourceRequests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer source.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorRequests.Add(1)
+	}))
+	defer mirror.Close()
+
+	client := NewClientWithBaseURL(source.URL,
+		WithCredentials(&Credentials{Token: "rubygems_key"}),
+		WithMirror(mirror.URL),
+		WithRetry(3, time.Millisecond))
+
+	var hostErr *GemHostError
+	if err := client.PushGem(strings.NewReader("gem")); !errors.As(err, &hostErr) {
+		t.Fatalf("expected *GemHostError, got %v", err)
+	}
+	if got := sourceRequests.Load(); got != 1 {
+		t.Errorf("source got %d requests, want 1", got)
+	}
+	if got := mirrorRequests.Load(); got != 0 {
+		t.Errorf("mirror got %d requests, want 0", got)
+	}
+}
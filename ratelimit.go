@@ -0,0 +1,86 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitState is the server's most recently reported rate-limit budget,
+// parsed from the X-RateLimit-Limit/Remaining/Reset response headers.
+type RateLimitState struct {
+	Limit     int       // Requests allowed in the current window
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets, zero if not reported
+	UpdatedAt time.Time // When these values were observed
+}
+
+// RateLimitState returns the last rate-limit state reported by the server.
+// The boolean is false if no response has carried rate-limit headers yet.
+func (c *Client) RateLimitState() (RateLimitState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rateLimit == nil {
+		return RateLimitState{}, false
+	}
+	return *c.rateLimit, true
+}
+
+// recordRateLimit stores rate-limit headers from resp, if present.
+func (c *Client) recordRateLimit(resp *http.Response) {
+	state, ok := parseRateLimitHeaders(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.rateLimit = &state
+	c.mu.Unlock()
+}
+
+// parseRateLimitHeaders parses X-RateLimit-* headers.
+// Missing or malformed headers are ignored; ok is false if none were usable.
+// X-RateLimit-Reset is accepted both as a Unix timestamp and as seconds from now.
+func parseRateLimitHeaders(h http.Header, now time.Time) (RateLimitState, bool) {
+	state := RateLimitState{UpdatedAt: now}
+	found := false
+
+	if v, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		state.Limit = v
+		found = true
+	}
+	if v, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		state.Remaining = v
+		found = true
+	}
+	if v, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil && v >= 0 {
+		// Values this large can only be epoch timestamps, not windows
+		const epochThreshold = 1_000_000_000
+		if v >= epochThreshold {
+			state.Reset = time.Unix(v, 0)
+		} else {
+			state.Reset = now.Add(time.Duration(v) * time.Second)
+		}
+		found = true
+	}
+
+	return state, found
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP-date. Dates in the past yield zero; ok is false if the
+// value is missing or malformed.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		headers   map[string]string
+		wantOK    bool
+		limit     int
+		remaining int
+		reset     time.Time
+	}{
+		{
+			name:    "no headers",
+			headers: map[string]string{},
+			wantOK:  false,
+		},
+		{
+			name: "all headers with epoch reset",
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "100",
+				"X-RateLimit-Remaining": "42",
+				"X-RateLimit-Reset":     "1767272400",
+			},
+			wantOK:    true,
+			limit:     100,
+			remaining: 42,
+			reset:     time.Unix(1767272400, 0),
+		},
+		{
+			name: "reset as seconds from now",
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "30",
+			},
+			wantOK:    true,
+			remaining: 0,
+			reset:     now.Add(30 * time.Second),
+		},
+		{
+			name: "malformed values are ignored",
+			headers: map[string]string{
+				"X-RateLimit-Limit":     "lots",
+				"X-RateLimit-Remaining": "",
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+
+			state, ok := parseRateLimitHeaders(h, now)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if state.Limit != tt.limit {
+				t.Errorf("Limit = %d, want %d", state.Limit, tt.limit)
+			}
+			if state.Remaining != tt.remaining {
+				t.Errorf("Remaining = %d, want %d", state.Remaining, tt.remaining)
+			}
+			if !state.Reset.Equal(tt.reset) {
+				t.Errorf("Reset = %v, want %v", state.Reset, tt.reset)
+			}
+		})
+	}
+}
+
+func TestClient_RateLimitState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "7")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if _, ok := client.RateLimitState(); ok {
+		t.Fatal("Expected no rate-limit state before any request")
+	}
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	state, ok := client.RateLimitState()
+	if !ok {
+		t.Fatal("Expected rate-limit state after request")
+	}
+	if state.Limit != 10 || state.Remaining != 7 {
+		t.Errorf("Expected 7/10 remaining, got %d/%d", state.Remaining, state.Limit)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", "120", 120 * time.Second, true},
+		{"zero seconds", "0", 0, true},
+		{"http date", now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"http date in past", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"negative", "-5", 0, false},
+		{"garbage", "soon", 0, false},
+		{"empty", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package rubygemsclient
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ConfigResolver resolves credentials and settings from one pair of Bundler
+// config files given explicitly, instead of the working directory and $HOME
+// the package-level functions use. A server handling many projects can keep
+// one resolver per project:
+//
+//	r := rubygems.NewConfigResolver("/srv/app/.bundle/config", "/home/deploy/.bundle/config")
+//	creds := r.CredentialsFor("rubygems.pkg.github.com")
+//
+// Both files are read once, by NewConfigResolver; create a new resolver to
+// pick up changes. BUNDLE_* environment variables and .netrc are process-wide
+// and are still consulted, in Bundler's usual order.
+type ConfigResolver struct {
+	localPath  string
+	globalPath string
+	local      *BundleConfig
+	global     *BundleConfig
+}
+
+// NewConfigResolver reads the local (project) and global (user) config files.
+// Either path may be "" or name a missing file, which is the same as an empty
+// config.
+func NewConfigResolver(localPath, globalPath string) *ConfigResolver {
+	return &ConfigResolver{
+		localPath:  localPath,
+		globalPath: globalPath,
+		local:      readConfigFile(localPath),
+		global:     readConfigFile(globalPath),
+	}
+}
+
+// LocalConfig returns the parsed local config, or nil if there is none.
+func (r *ConfigResolver) LocalConfig() *BundleConfig {
+	return r.local
+}
+
+// GlobalConfig returns the parsed global config, or nil if there is none.
+func (r *ConfigResolver) GlobalConfig() *BundleConfig {
+	return r.global
+}
+
+// CredentialsFor resolves credentials for a host like the package-level
+// CredentialsFor, reading this resolver's files.
+func (r *ConfigResolver) CredentialsFor(host string) *Credentials {
+	creds, _ := r.CredentialsForWithSource(host)
+	return creds
+}
+
+// CredentialsForWithSource resolves credentials like CredentialsFor and also
+// reports which source supplied them, or SourceNone with nil credentials.
+func (r *ConfigResolver) CredentialsForWithSource(host string) (*Credentials, CredentialSource) {
+	// 1. Check local .bundle/config first (highest priority)
+	if creds := r.local.CredentialsForHost(host); creds != nil {
+		return creds, SourceLocalConfig
+	}
+
+	// 2. Check environment variable
+	if creds := CredentialsFromEnv(host); creds != nil {
+		return creds, SourceEnv
+	}
+
+	// 3. Check global ~/.bundle/config
+	if creds := r.global.CredentialsForHost(host); creds != nil {
+		return creds, SourceGlobalConfig
+	}
+
+	// 4. Fall back to .netrc (lowest priority)
+	if creds := CredentialsFromNetrc(host); creds != nil {
+		return creds, SourceNetrc
+	}
+
+	return nil, SourceNone
+}
+
+// CredentialsForMatching resolves credentials for a host like the
+// package-level CredentialsForMatching, reading this resolver's files.
+func (r *ConfigResolver) CredentialsForMatching(host string, mode HostMatchMode) *Credentials {
+	if creds := r.CredentialsFor(host); creds != nil || mode == HostMatchExact {
+		return creds
+	}
+
+	for _, parent := range parentDomains(host) {
+		if creds, source := r.CredentialsForWithSource(parent); creds != nil && source != SourceNetrc {
+			return creds
+		}
+	}
+	return nil
+}
+
+// CredentialsForURL resolves credentials for a request URL like the
+// package-level CredentialsForURL, honoring path-scoped entries.
+func (r *ConfigResolver) CredentialsForURL(rawURL string) *Credentials {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	for i := len(segments); i > 0; i-- {
+		key := uriToEnvKey(u.Scheme, u.Host, segments[:i])
+		if creds := r.local.credentialsForKey(key); creds != nil {
+			return creds
+		}
+		if creds := r.global.credentialsForKey(key); creds != nil {
+			return creds
+		}
+	}
+
+	return r.CredentialsFor(u.Host)
+}
+
+// Setting returns a Bundler setting like the package-level Setting, checking
+// the local config, the environment, then the global config.
+func (r *ConfigResolver) Setting(name string) (string, bool) {
+	key := settingKey(name)
+	if _, setting := classifyConfigKey(key); !setting {
+		return "", false
+	}
+
+	if value, ok := r.local.Setting(key); ok {
+		return value, true
+	}
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	return r.global.Setting(key)
+}
@@ -0,0 +1,169 @@
+package rubygemsclient
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigResolver_CredentialsFor(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("BUNDLE_ENV__EXAMPLE__COM", "any:env_token")
+	t.Setenv("BUNDLE_SHARED__EXAMPLE__COM", "any:env_shared")
+
+	local := writeConfigFile(t, `---
+BUNDLE_LOCAL__EXAMPLE__COM: "any:local_token"
+BUNDLE_SHARED__EXAMPLE__COM: "any:local_shared"
+`)
+	global := writeConfigFile(t, `---
+BUNDLE_GLOBAL__EXAMPLE__COM: "any:global_token"
+BUNDLE_SHARED__EXAMPLE__COM: "any:global_shared"
+BUNDLE_ENV__EXAMPLE__COM: "any:global_env"
+`)
+	r := NewConfigResolver(local, global)
+
+	tests := []struct {
+		host       string
+		wantToken  string
+		wantSource CredentialSource
+	}{
+		{"local.example.com", "local_token", SourceLocalConfig},
+		{"shared.example.com", "local_shared", SourceLocalConfig},
+		{"env.example.com", "env_token", SourceEnv},
+		{"global.example.com", "global_token", SourceGlobalConfig},
+		{"unknown.example.com", "", SourceNone},
+	}
+	for _, tt := range tests {
+		creds, source := r.CredentialsForWithSource(tt.host)
+		if source != tt.wantSource {
+			t.Errorf("%s: source = %v, want %v", tt.host, source, tt.wantSource)
+		}
+		if got := creds.GetToken(); got != tt.wantToken {
+			t.Errorf("%s: token = %q, want %q", tt.host, got, tt.wantToken)
+		}
+	}
+
+	// Independent of the package-level configs
+	useBundleConfigs(t, "", "")
+	if creds := CredentialsFor("local.example.com"); creds != nil {
+		t.Errorf("package-level CredentialsFor saw resolver config: %+v", creds)
+	}
+}
+
+func TestConfigResolver_CredentialsForMatching(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	local := writeConfigFile(t, `---
+BUNDLE_CORP__EXAMPLE__COM: "any:corp_token"
+BUNDLE_EXACT__CORP__EXAMPLE__COM: "any:exact_token"
+`)
+	r := NewConfigResolver(local, "")
+
+	tests := []struct {
+		host      string
+		mode      HostMatchMode
+		wantToken string
+	}{
+		{"gems.corp.example.com", HostMatchExact, ""},
+		{"gems.corp.example.com", HostMatchSuffix, "corp_token"},
+		{"exact.corp.example.com", HostMatchSuffix, "exact_token"},
+	}
+	for _, tt := range tests {
+		if got := r.CredentialsForMatching(tt.host, tt.mode).GetToken(); got != tt.wantToken {
+			t.Errorf("%s (mode %d): token = %q, want %q", tt.host, tt.mode, got, tt.wantToken)
+		}
+	}
+
+	// Independent of the package-level configs
+	useBundleConfigs(t, "", "")
+	if creds := CredentialsForMatching("gems.corp.example.com", HostMatchSuffix); creds != nil {
+		t.Errorf("package-level CredentialsForMatching saw resolver config: %+v", creds)
+	}
+}
+
+func TestConfigResolver_MissingFiles(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	r := NewConfigResolver("", filepath.Join(t.TempDir(), "missing"))
+
+	if r.LocalConfig() != nil || r.GlobalConfig() != nil {
+		t.Error("expected no configs")
+	}
+	if creds := r.CredentialsFor("gems.example.com"); creds != nil {
+		t.Errorf("got %+v, want nil", creds)
+	}
+	if _, ok := r.Setting("path"); ok {
+		t.Error("unexpected setting")
+	}
+}
+
+func TestConfigResolver_CredentialsForURL(t *testing.T) {
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	local := writeConfigFile(t, `---
+BUNDLE_HTTPS://GEMS__EXAMPLE__COM/TEAM/: "any:team_token"
+`)
+	global := writeConfigFile(t, `---
+BUNDLE_GEMS__EXAMPLE__COM: "any:host_token"
+BUNDLE_PATH: "vendor/bundle"
+`)
+	r := NewConfigResolver(local, global)
+
+	if got := r.CredentialsForURL("https://gems.example.com/team/api/v1").GetToken(); got != "team_token" {
+		t.Errorf("path-scoped token = %q, want team_token", got)
+	}
+	if got := r.CredentialsForURL("https://gems.example.com/other").GetToken(); got != "host_token" {
+		t.Errorf("host token = %q, want host_token", got)
+	}
+	if got, ok := r.Setting("path"); !ok || got != "vendor/bundle" {
+		t.Errorf("Setting(path) = %q, %v", got, ok)
+	}
+}
+
+func TestCredentialsFor_ConcurrentReload(t *testing.T) {
+	useBundleConfigs(t,
+		"---\nBUNDLE_GEMS__EXAMPLE__COM: \"any:local_token\"\nBUNDLE_PATH: vendor\n",
+		"---\nBUNDLE_GLOBAL__EXAMPLE__COM: \"any:global_token\"\n")
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if got := CredentialsFor("gems.example.com").GetToken(); got != "local_token" {
+					t.Errorf("token = %q during reload, want local_token", got)
+					return
+				}
+				CredentialsForURL("https://global.example.com/")
+				CredentialsFromNetrc("gems.example.com")
+				LoadGemCredentials()
+				Setting("path")
+			}
+		}()
+	}
+
+	for i := range 50 {
+		if i%10 == 9 {
+			ResetConfigCache()
+		} else {
+			ReloadConfigs()
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,151 @@
+package rubygemsclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// retryPolicy controls how transient failures are retried.
+// The zero value makes a single attempt.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	retryIf     func(*http.Response, error) bool // nil means defaultRetryIf
+}
+
+// WithRetry retries requests that fail with 502, 503, or 504, or with a
+// timeout or dropped connection, up to maxAttempts total attempts. Delays grow
+// exponentially from baseDelay with jitter, up to a minute, and waiting
+// stops as soon as the request's context is canceled. A 429 is retried after
+// the delay given in its Retry-After header. Other statuses (404, 401, ...) are returned
+// immediately. Only GET and HEAD requests are retried; pushes and yanks are
+// sent once.
+func WithRetry(maxAttempts int, baseDelay time.Duration) ClientOption {
+	return func(c *Client) {
+		c.retry.maxAttempts = maxAttempts
+		c.retry.baseDelay = baseDelay
+	}
+}
+
+// WithRetryPredicate replaces the built-in decision of what WithRetry
+// retries. After each attempt fn is called with either the response or the
+// transport error, and a true result retries the request. It is
+// consulted on every attempt, but never for requests other than GET and
+// HEAD, once the request's context is done, or for a 429, which always
+// waits for Retry-After. Pass nil to restore the default: 502, 503, 504,
+// timeouts and dropped connections.
+func WithRetryPredicate(fn func(resp *http.Response, err error) bool) ClientOption {
+	return func(c *Client) {
+		c.retry.retryIf = fn
+	}
+}
+
+// shouldRetry reports whether a failed attempt is worth retrying under the
+// policy's predicate.
+func (p retryPolicy) shouldRetry(resp *http.Response, err error) bool {
+	if p.retryIf != nil {
+		return p.retryIf(resp, err)
+	}
+	return defaultRetryIf(resp, err)
+}
+
+// defaultRetryIf is the built-in retry predicate.
+func defaultRetryIf(resp *http.Response, err error) bool {
+	if err != nil {
+		return retryableError(err)
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+// retryableMethod reports whether requests with method are safe to repeat.
+func retryableMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryableError reports whether a transport error is worth retrying:
+// timeouts, refused or reset connections, and connections closed early.
+// Everything else, such as certificate failures, unknown hosts, bad URLs,
+// redirect-policy failures and a canceled context, is final.
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &certErr) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostname) || errors.As(err, &invalid) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// maxRetryDelay caps the exponential backoff between retries.
+const maxRetryDelay = time.Minute
+
+// backoff returns the jittered delay before retry number attempt (1-based):
+// a random duration in [d/2, d) where d = baseDelay * 2^(attempt-1), capped
+// at maxRetryDelay.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	if p.baseDelay <= 0 {
+		return 0
+	}
+	d := maxRetryDelay
+	// Doubling past the cap, or far enough to overflow, stays at the cap
+	if shift := attempt - 1; shift < 63 && p.baseDelay <= maxRetryDelay>>shift {
+		d = p.baseDelay << shift
+	}
+	half := d / 2
+	return half + rand.N(d-half)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardBody drains and closes a response that is being retried so its
+// connection can be reused.
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
@@ -0,0 +1,511 @@
+package rubygemsclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithRetry_SucceedsAfterTransientFailures(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond))
+
+	info, err := client.GetGemInfo("test-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "test-gem" {
+		t.Errorf("Expected test-gem, got %s", info.Name)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWithRetry_NonRetryableStatusFailsFast(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusUnauthorized} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL, WithRetry(5, time.Millisecond))
+
+			if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+				t.Fatal("Expected error")
+			}
+			if got := attempts.Load(); got != 1 {
+				t.Errorf("Expected 1 attempt, got %d", got)
+			}
+		})
+	}
+}
+
+func TestWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond))
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWithRetry_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close() // Nothing listens here any more
+
+	client := NewClientWithBaseURL(url, WithRetry(2, time.Millisecond))
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+		t.Fatal("Expected connection error")
+	}
+}
+
+func TestWithRetry_ContextCanceledDuringBackoff(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(5, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetGemInfoContext(ctx, "test-gem", "1.0.0")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected 1 attempt before cancellation, got %d", got)
+	}
+}
+
+func TestWithRetryPredicate_ConsultedPerAttempt(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusNotFound) // e.g. a CDN that has not caught up
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	var calls atomic.Int32
+	client := NewClientWithBaseURL(server.URL,
+		WithRetryPredicate(func(resp *http.Response, err error) bool {
+			calls.Add(1)
+			return err == nil && resp.StatusCode == http.StatusNotFound
+		}),
+		WithRetry(5, time.Millisecond),
+	)
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected the predicate to see all 3 attempts, got %d", got)
+	}
+}
+
+func TestWithRetryPredicate_OverridesStatusPolicy(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL,
+		WithRetry(5, time.Millisecond),
+		WithRetryPredicate(func(*http.Response, error) bool { return false }),
+	)
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+		t.Fatal("Expected error")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
+func TestWithRetryPredicate_SeesTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var errs atomic.Int32
+	client := NewClientWithBaseURL(url,
+		WithRetry(3, time.Millisecond),
+		WithRetryPredicate(func(resp *http.Response, err error) bool {
+			if err != nil && resp == nil {
+				errs.Add(1)
+			}
+			return true
+		}),
+	)
+
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err == nil {
+		t.Fatal("Expected connection error")
+	}
+	if got := errs.Load(); got != 2 {
+		t.Errorf("Expected the predicate to see 2 connection errors, got %d", got)
+	}
+}
+
+func TestWithRetry_TruncatedBody(t *testing.T) {
+	full, _ := json.Marshal(GemInfo{Name: "test-gem", Version: "1.0.0"})
+	truncations := map[string]func(w http.ResponseWriter){
+		// The proxy closes the connection before Content-Length bytes
+		"short read": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Length", strconv.Itoa(len(full)))
+			_, _ = w.Write(full[:len(full)/2])
+		},
+		// The proxy forwards a cut-down body with a matching Content-Length
+		"cut document": func(w http.ResponseWriter) {
+			_, _ = w.Write(full[:len(full)/2])
+		},
+	}
+	for name, truncate := range truncations {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					truncate(w)
+					return
+				}
+				_, _ = w.Write(full)
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond))
+			info, err := client.GetGemInfo("test-gem", "1.0.0")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if info.Name != "test-gem" {
+				t.Errorf("Expected test-gem, got %s", info.Name)
+			}
+			if got := attempts.Load(); got != 2 {
+				t.Errorf("Expected 2 attempts, got %d", got)
+			}
+
+			// Without a retry policy the truncation is reported
+			attempts.Store(0)
+			if _, err := NewClientWithBaseURL(server.URL).GetGemInfo("test-gem", "1.0.0"); !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWithRetry_TruncatedVersions(t *testing.T) {
+	full := []byte(`[{"number":"2.0.0"},{"number":"1.0.0"}]`)
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			_, _ = w.Write(full[:len(full)/2])
+			return
+		}
+		_, _ = w.Write(full)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond))
+	versions, err := client.GetGemVersions("test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Errorf("Expected 2 versions, got %v", versions)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestWithRetry_TruncatedBodyWithCache(t *testing.T) {
+	full, _ := json.Marshal(GemInfo{Name: "test-gem", Version: "1.0.0"})
+	var attempts, conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			// The server still has the same document; only the proxy cut it
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if attempts.Add(1) == 1 {
+			_, _ = w.Write(full[:len(full)/2])
+			return
+		}
+		_, _ = w.Write(full)
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache()
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond), WithCache(cache))
+	info, err := client.GetGemInfo("test-gem", "1.0.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Name != "test-gem" {
+		t.Errorf("Expected test-gem, got %s", info.Name)
+	}
+	if got := conditional.Load(); got != 0 {
+		t.Errorf("Expected the retry to skip the cached validators, got %d conditional requests", got)
+	}
+
+	// The complete body replaced the truncated one and is revalidated as usual
+	if _, err := client.GetGemInfo("test-gem", "1.0.0"); err != nil {
+		t.Fatalf("Unexpected error from cache: %v", err)
+	}
+	if got := conditional.Load(); got != 1 {
+		t.Errorf("Expected 1 conditional request, got %d", got)
+	}
+}
+
+func TestWithRetry_TruncatedBodyNotRetriedForPush(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Content-Length", "100")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte("Repushing"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond), WithCredentials(&Credentials{Token: "key"}))
+	if err := client.PushGem(strings.NewReader("gem")); err == nil {
+		t.Fatal("Expected error")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("Expected 1 attempt, got %d", got)
+	}
+}
+
+func TestRetryableError(t *testing.T) {
+	urlErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://rubygems.org/api/v1/gems/rack.json", Err: err}
+	}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", urlErr(&net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}), true},
+		{"connection reset", urlErr(&net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"connection refused", urlErr(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), true},
+		{"unexpected EOF", urlErr(io.ErrUnexpectedEOF), true},
+		{"EOF", urlErr(io.EOF), true},
+		{"DNS timeout", urlErr(&net.DNSError{Name: "rubygems.org", IsTimeout: true}), true},
+
+		{"unknown authority", urlErr(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), false},
+		{"hostname mismatch", urlErr(x509.HostnameError{Host: "rubygems.org"}), false},
+		{"no such host", urlErr(&net.DNSError{Name: "rubygems.invalid", IsNotFound: true}), false},
+		{"unsupported protocol", urlErr(errors.New(`unsupported protocol scheme "ftp"`)), false},
+		{"malformed URL", &url.Error{Op: "parse", URL: "http://[::1", Err: errors.New("missing ']' in host")}, false},
+		{"canceled", urlErr(context.Canceled), false},
+		{"deadline exceeded", urlErr(context.DeadlineExceeded), false},
+		{"too many redirects", urlErr(&TooManyRedirectsError{Max: 10}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableError(tt.err); got != tt.want {
+				t.Errorf("retryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry_CertificateErrorNotRetried(t *testing.T) {
+	var handshakes atomic.Int32
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			handshakes.Add(1)
+		}
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The default transport does not trust the test server's certificate
+	client := NewClientWithBaseURL(server.URL, WithRetry(3, time.Millisecond))
+	if _, err := client.GetGemInfo("test-gem", ""); err == nil {
+		t.Fatal("Expected a certificate error")
+	}
+	if got := handshakes.Load(); got != 1 {
+		t.Errorf("Expected 1 connection, got %d", got)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{maxAttempts: 5, baseDelay: 100 * time.Millisecond}
+
+	for attempt := 1; attempt <= 4; attempt++ {
+		full := p.baseDelay << (attempt - 1)
+		for range 20 {
+			d := p.backoff(attempt)
+			if d < full/2 || d >= full {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v)", attempt, d, full/2, full)
+			}
+		}
+	}
+
+	if d := (retryPolicy{}).backoff(1); d != 0 {
+		t.Errorf("Expected zero backoff without base delay, got %v", d)
+	}
+}
+
+func TestRetryPolicy_BackoffIsCapped(t *testing.T) {
+	tests := []struct {
+		baseDelay time.Duration
+		attempt   int
+	}{
+		{time.Second, 10},           // Doubling passes the cap
+		{time.Second, 64},           // The shift alone would overflow
+		{time.Second, 1000},         // Long-running retry loops
+		{2 * maxRetryDelay, 1},      // A base delay above the cap
+		{time.Duration(1) << 62, 2}, // Shifting would wrap negative
+	}
+	for _, tt := range tests {
+		d := retryPolicy{baseDelay: tt.baseDelay}.backoff(tt.attempt)
+		if d < maxRetryDelay/2 || d >= maxRetryDelay {
+			t.Errorf("backoff(%d) with base %v = %v, want in [%v, %v)",
+				tt.attempt, tt.baseDelay, d, maxRetryDelay/2, maxRetryDelay)
+		}
+	}
+}
+
+func TestRateLimited_ReturnsTypedErrorWithoutRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       time.Duration
+	}{
+		{"seconds", "30", 30 * time.Second},
+		// HTTP-dates have one-second resolution, so stay well clear of rounding
+		{"http date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", tt.retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL)
+
+			_, err := client.GetGemInfo("test-gem", "1.0.0")
+			var rateLimited *RateLimitedError
+			if !errors.As(err, &rateLimited) {
+				t.Fatalf("Expected *RateLimitedError, got %v", err)
+			}
+			if diff := tt.want - rateLimited.RetryAfter; diff < 0 || diff > 2*time.Second {
+				t.Errorf("Expected RetryAfter about %v, got %v", tt.want, rateLimited.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRateLimited_RetriesAfterDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter func() string
+	}{
+		{"seconds", func() string { return "1" }},
+		{"http date", func() string { return time.Now().Add(time.Second).UTC().Format(http.TimeFormat) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter())
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+			}))
+			defer server.Close()
+
+			// A base delay of an hour proves the wait came from Retry-After
+			client := NewClientWithBaseURL(server.URL, WithRetry(2, time.Hour))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if _, err := client.GetGemInfoContext(ctx, "test-gem", "1.0.0"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := attempts.Load(); got != 2 {
+				t.Errorf("Expected 2 attempts, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRateLimited_ContextCanceledWhileWaiting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithRetry(2, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetGemInfoContext(ctx, "test-gem", "1.0.0")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
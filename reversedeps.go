@@ -0,0 +1,56 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// DefaultReverseDependencyLimit caps GetReverseDependencies results unless
+// overridden. Popular gems have tens of thousands of dependents.
+const DefaultReverseDependencyLimit = 1000
+
+// reverseDependencyOptions configures reverse-dependency lookups.
+type reverseDependencyOptions struct {
+	limit int
+}
+
+// ReverseDependencyOption configures GetReverseDependencies.
+type ReverseDependencyOption func(*reverseDependencyOptions)
+
+// ReverseDependencyLimit caps the number of names returned.
+// Zero or a negative value returns every dependent.
+func ReverseDependencyLimit(n int) ReverseDependencyOption {
+	return func(o *reverseDependencyOptions) {
+		o.limit = n
+	}
+}
+
+// GetReverseDependencies returns the names of gems that depend on name, from
+// /gems/<gem>/reverse_dependencies.json, sorted alphabetically. At most
+// DefaultReverseDependencyLimit names are returned unless
+// ReverseDependencyLimit says otherwise.
+func (c *Client) GetReverseDependencies(name string, opts ...ReverseDependencyOption) ([]string, error) {
+	return c.GetReverseDependenciesContext(context.Background(), name, opts...)
+}
+
+// GetReverseDependenciesContext is like GetReverseDependencies but aborts when ctx is canceled.
+func (c *Client) GetReverseDependenciesContext(ctx context.Context, name string, opts ...ReverseDependencyOption) ([]string, error) {
+	o := reverseDependencyOptions{limit: DefaultReverseDependencyLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var names []string
+	endpoint := fmt.Sprintf("%s/gems/%s/reverse_dependencies.json", c.baseURL, url.PathEscape(name))
+	if err := c.getJSON(ctx, endpoint, name, "reverse dependencies", &names); err != nil {
+		return nil, err
+	}
+
+	slices.Sort(names)
+	if o.limit > 0 && len(names) > o.limit {
+		names = names[:o.limit]
+	}
+	return names, nil
+}
@@ -0,0 +1,62 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func newReverseDepsServer(t *testing.T, names []string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/gems/rack/reverse_dependencies.json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(names)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetReverseDependencies(t *testing.T) {
+	server := newReverseDepsServer(t, []string{"sinatra", "rails", "puma"})
+	client := NewClientWithBaseURL(server.URL)
+
+	names, err := client.GetReverseDependencies("rack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"puma", "rails", "sinatra"}; !slices.Equal(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestGetReverseDependencies_Limit(t *testing.T) {
+	var all []string
+	for i := range DefaultReverseDependencyLimit + 50 {
+		all = append(all, fmt.Sprintf("gem-%05d", i))
+	}
+	server := newReverseDepsServer(t, all)
+	client := NewClientWithBaseURL(server.URL)
+
+	names, err := client.GetReverseDependencies("rack")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) != DefaultReverseDependencyLimit {
+		t.Errorf("default limit: got %d names, want %d", len(names), DefaultReverseDependencyLimit)
+	}
+
+	names, _ = client.GetReverseDependencies("rack", ReverseDependencyLimit(10))
+	if len(names) != 10 || names[9] != "gem-00009" {
+		t.Errorf("limit 10: got %d names ending %q", len(names), names[len(names)-1])
+	}
+
+	names, _ = client.GetReverseDependencies("rack", ReverseDependencyLimit(0))
+	if len(names) != len(all) {
+		t.Errorf("no limit: got %d names, want %d", len(names), len(all))
+	}
+}
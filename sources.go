@@ -0,0 +1,89 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Source is one gem server in a SourceSet.
+type Source struct {
+	URL    string  // Base URL as configured, without userinfo, e.g. "https://gem.fury.io/acme"
+	Client *Client // Client for this source, with its resolved credentials
+}
+
+// SourceSet holds several gem sources in priority order, like the sources
+// of a Gemfile, and looks gems up across them.
+// Ruby equivalent: Bundler::SourceList
+type SourceSet struct {
+	sources []*Source
+}
+
+// NewSourceSet creates a source for each base URL, in order. Credentials
+// embedded in a URL are used for that source; otherwise they are resolved
+// with CredentialsForURL, which honors path-scoped entries and falls back to
+// CredentialsFor on the source's host, or to CredentialsForMatching under
+// WithHostMatchMode(HostMatchSuffix). opts apply to every source and may
+// override them. All sources share one connection pool.
+func NewSourceSet(urls []string, opts ...ClientOption) (*SourceSet, error) {
+	shared := newPooledHTTPClient()
+	set := &SourceSet{sources: make([]*Source, 0, len(urls))}
+
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid source URL %q", raw)
+		}
+
+		sourceOpts := append([]ClientOption{func(c *Client) { c.httpClient = shared }}, opts...)
+		client := NewClientWithBaseURL(strings.TrimSuffix(raw, "/"), sourceOpts...)
+		if client.credentials == nil {
+			client.credentials = CredentialsForURL(raw)
+			if client.credentials == nil && client.hostMatch != HostMatchExact {
+				client.credentials = CredentialsForMatching(u.Host, client.hostMatch)
+			}
+		}
+
+		set.sources = append(set.sources, &Source{
+			URL:    stripUserinfo(raw),
+			Client: client,
+		})
+	}
+
+	return set, nil
+}
+
+// Sources returns the sources in priority order.
+func (s *SourceSet) Sources() []*Source {
+	return s.sources
+}
+
+// GetGemInfo looks the gem up in each source in order and returns the first
+// successful result together with the source that served it. If every
+// source fails, the error joins each source's failure, so
+// errors.Is(err, ErrGemNotFound) reports a gem missing everywhere.
+func (s *SourceSet) GetGemInfo(name, version string) (*GemInfo, *Source, error) {
+	return s.GetGemInfoContext(context.Background(), name, version)
+}
+
+// GetGemInfoContext is like GetGemInfo but aborts when ctx is canceled.
+func (s *SourceSet) GetGemInfoContext(ctx context.Context, name, version string) (*GemInfo, *Source, error) {
+	if len(s.sources) == 0 {
+		return nil, nil, errors.New("no gem sources configured")
+	}
+
+	var errs []error
+	for _, source := range s.sources {
+		info, err := source.Client.GetGemInfoContext(ctx, name, version)
+		if err == nil {
+			return info, source, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", source.URL, err))
+	}
+	return nil, nil, errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSourceServer serves the given gems and records the Authorization
+// header of the last request.
+func newSourceServer(t *testing.T, gems map[string]bool, lastAuth *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*lastAuth = r.Header.Get("Authorization")
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/gems/"), ".json")
+		if !gems[name] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: name, Version: "1.0.0"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSourceSet_TriesSourcesInOrder(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+	t.Chdir(t.TempDir())
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	var publicAuth, privateAuth string
+	public := newSourceServer(t, map[string]bool{"rack": true}, &publicAuth)
+	private := newSourceServer(t, map[string]bool{"rack": true, "acme-internal": true}, &privateAuth)
+
+	// Credential keys ignore ports, so give the public source its own host name
+	publicURL := strings.Replace(public.URL, "127.0.0.1", "localhost", 1)
+	t.Setenv(hostToEnvKey(strings.TrimPrefix(private.URL, "http://")), "any:fury-token")
+
+	set, err := NewSourceSet([]string{publicURL, private.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, source, err := set.GetGemInfo("rack", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.URL != publicURL || info.Name != "rack" {
+		t.Errorf("expected rack from the first source, got %s", source.URL)
+	}
+
+	info, source, err = set.GetGemInfo("acme-internal", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source.URL != private.URL || info.Name != "acme-internal" {
+		t.Errorf("expected acme-internal from the private source, got %s", source.URL)
+	}
+	if privateAuth != "Bearer fury-token" {
+		t.Errorf("private source Authorization = %q, want its own credentials", privateAuth)
+	}
+	if publicAuth != "" {
+		t.Errorf("public source got credentials %q", publicAuth)
+	}
+}
+
+func TestSourceSet_NotFoundEverywhere(t *testing.T) {
+	var auth string
+	a := newSourceServer(t, nil, &auth)
+	b := newSourceServer(t, nil, &auth)
+
+	set, err := NewSourceSet([]string{a.URL, b.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _, err = set.GetGemInfo("missing", "1.0.0")
+	if !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("expected ErrGemNotFound, got %v", err)
+	}
+	if !strings.Contains(err.Error(), a.URL) || !strings.Contains(err.Error(), b.URL) {
+		t.Errorf("expected error to name every source, got %v", err)
+	}
+}
+
+func TestNewSourceSet_InvalidURL(t *testing.T) {
+	if _, err := NewSourceSet([]string{"not a url"}); err == nil {
+		t.Error("expected error for invalid source URL")
+	}
+}
+
+func TestNewSourceSet_EmbeddedCredentials(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+	t.Chdir(t.TempDir())
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+
+	var auth string
+	server := newSourceServer(t, map[string]bool{"rack": true}, &auth)
+
+	// Config credentials for the host lose to the ones in the URL
+	t.Setenv(hostToEnvKey(strings.TrimPrefix(server.URL, "http://")), "any:config-token")
+
+	set, err := NewSourceSet([]string{strings.Replace(server.URL, "http://", "http://any:url-token@", 1)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, source, err := set.GetGemInfo("rack", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if auth != "Bearer url-token" {
+		t.Errorf("Authorization = %q, want the URL's token", auth)
+	}
+	if strings.Contains(source.URL, "url-token") {
+		t.Errorf("Source.URL leaks credentials: %s", source.URL)
+	}
+}
+
+func TestNewSourceSet_HostMatchMode(t *testing.T) {
+	ResetConfigCache()
+	t.Cleanup(ResetConfigCache)
+	t.Chdir(t.TempDir())
+	t.Setenv("BUNDLE_USER_HOME", t.TempDir())
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("BUNDLE_CORP__EXAMPLE__COM", "any:corp_token")
+
+	urls := []string{"https://gems.corp.example.com"}
+	set, err := NewSourceSet(urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds := set.Sources()[0].Client.credentials; creds != nil {
+		t.Errorf("expected no credentials in exact mode, got %v", creds)
+	}
+
+	set, err = NewSourceSet(urls, WithHostMatchMode(HostMatchSuffix))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := set.Sources()[0].Client.credentials.GetToken(); got != "corp_token" {
+		t.Errorf("expected the parent domain's corp_token, got %q", got)
+	}
+}
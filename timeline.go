@@ -0,0 +1,105 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// VersionRelease is a version placed on a gem's release timeline.
+type VersionRelease struct {
+	VersionInfo
+
+	// MissingDate is true when the server gave no created_at; such versions
+	// are placed at the end of the timeline.
+	MissingDate bool
+}
+
+// GetVersionTimeline returns all versions of a gem ordered by release date,
+// oldest first. Versions without a created_at come last, in server order.
+func (c *Client) GetVersionTimeline(name string) ([]VersionRelease, error) {
+	return c.GetVersionTimelineContext(context.Background(), name)
+}
+
+// GetVersionTimelineContext is like GetVersionTimeline but aborts when ctx is canceled.
+func (c *Client) GetVersionTimelineContext(ctx context.Context, name string) ([]VersionRelease, error) {
+	versions, err := c.fetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := make([]VersionRelease, len(versions))
+	for i, v := range versions {
+		timeline[i] = VersionRelease{VersionInfo: v, MissingDate: v.CreatedAt.IsZero()}
+	}
+
+	slices.SortStableFunc(timeline, func(a, b VersionRelease) int {
+		switch {
+		case a.MissingDate && b.MissingDate:
+			return 0
+		case a.MissingDate:
+			return 1
+		case b.MissingDate:
+			return -1
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	})
+
+	return timeline, nil
+}
+
+// releaseOptions configures release-age queries.
+type releaseOptions struct {
+	includePrereleases bool
+}
+
+// ReleaseOption configures release queries such as DaysSinceLastRelease,
+// LatestPerMajor and GetLatestVersion.
+type ReleaseOption func(*releaseOptions)
+
+// IncludePrereleases counts prerelease versions as releases.
+func IncludePrereleases() ReleaseOption {
+	return func(o *releaseOptions) {
+		o.includePrereleases = true
+	}
+}
+
+// DaysSinceLastRelease returns how many whole days have passed since the
+// gem's newest release. Prereleases are ignored unless IncludePrereleases is
+// given. Returns ErrNoMatchingVersion if the gem has no dated releases.
+func (c *Client) DaysSinceLastRelease(name string, opts ...ReleaseOption) (int, error) {
+	return c.DaysSinceLastReleaseContext(context.Background(), name, opts...)
+}
+
+// DaysSinceLastReleaseContext is like DaysSinceLastRelease but aborts when ctx is canceled.
+func (c *Client) DaysSinceLastReleaseContext(ctx context.Context, name string, opts ...ReleaseOption) (int, error) {
+	var o releaseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	timeline, err := c.GetVersionTimelineContext(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest time.Time
+	for _, r := range timeline {
+		if r.MissingDate {
+			continue
+		}
+		if !o.includePrereleases && (r.Prerelease || IsPrerelease(r.Number)) {
+			continue
+		}
+		if r.CreatedAt.After(latest) {
+			latest = r.CreatedAt
+		}
+	}
+
+	if latest.IsZero() {
+		return 0, fmt.Errorf("%w: %s has no releases", ErrNoMatchingVersion, name)
+	}
+	return int(time.Since(latest).Hours() / 24), nil
+}
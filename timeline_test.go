@@ -0,0 +1,92 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetVersionTimeline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"number":"2.0.0","created_at":"2025-06-01T00:00:00Z"},
+			{"number":"0.1.0"},
+			{"number":"1.0.0","created_at":"2024-01-01T00:00:00Z"},
+			{"number":"1.5.0","created_at":"2024-09-15T00:00:00Z"},
+			{"number":"0.2.0"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	timeline, err := client.GetVersionTimeline("test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []struct {
+		number  string
+		missing bool
+	}{
+		{"1.0.0", false}, {"1.5.0", false}, {"2.0.0", false}, {"0.1.0", true}, {"0.2.0", true},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Expected %d releases, got %d", len(want), len(timeline))
+	}
+	for i, w := range want {
+		if timeline[i].Number != w.number || timeline[i].MissingDate != w.missing {
+			t.Errorf("Release %d = %s (missing=%v), want %s (missing=%v)",
+				i, timeline[i].Number, timeline[i].MissingDate, w.number, w.missing)
+		}
+	}
+}
+
+func TestDaysSinceLastRelease(t *testing.T) {
+	now := time.Now().UTC()
+	versions := []VersionInfo{
+		{Number: "2.0.0.beta1", CreatedAt: now.Add(-2 * 24 * time.Hour), Prerelease: true},
+		{Number: "1.1.0", CreatedAt: now.Add(-10*24*time.Hour - time.Hour)},
+		{Number: "1.0.0", CreatedAt: now.Add(-400 * 24 * time.Hour)},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions/unreleased.json" {
+			_ = json.NewEncoder(w).Encode([]VersionInfo{{Number: "0.1.0.pre", Prerelease: true, CreatedAt: now}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(versions)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	days, err := client.DaysSinceLastRelease("test-gem")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if days != 10 {
+		t.Errorf("Expected 10 days (prerelease excluded), got %d", days)
+	}
+
+	days, err = client.DaysSinceLastRelease("test-gem", IncludePrereleases())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if days != 2 {
+		t.Errorf("Expected 2 days with prereleases, got %d", days)
+	}
+
+	if _, err := client.DaysSinceLastRelease("unreleased"); !errors.Is(err, ErrNoMatchingVersion) {
+		t.Errorf("Expected ErrNoMatchingVersion for prerelease-only gem, got %v", err)
+	}
+}
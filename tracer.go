@@ -0,0 +1,54 @@
+package rubygemsclient
+
+import "context"
+
+// Tracer starts a span for each HTTP request the client sends. It is a
+// minimal subset of OpenTelemetry's trace.Tracer, so an adapter around an
+// OpenTelemetry tracer takes a few lines and this package needs no tracing
+// dependency.
+type Tracer interface {
+	// Start begins a span named name as a child of any span in ctx, and
+	// returns a context carrying the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced request, as started by a Tracer.
+type Span interface {
+	// SetAttribute records a string or int attribute.
+	SetAttribute(key string, value any)
+	// RecordError marks the span failed with err.
+	RecordError(err error)
+	// End finishes the span.
+	End()
+}
+
+// Attributes set on request spans.
+const (
+	SpanAttrGemName    = "gem.name"          // Gem the request is for, when there is one
+	SpanAttrStatusCode = "http.status_code"  // Response status, unset if none was received
+	SpanAttrEndpoint   = "rubygems.endpoint" // Request path, e.g. "/api/v1/gems/rack.json"
+)
+
+// WithTracer traces every HTTP request the client sends, retries included,
+// as a span named "HTTP <method>" carrying the SpanAttr attributes. The span's
+// context is passed to the transport, so an instrumented transport can
+// propagate it.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+// gemNameKey carries the gem a request is for, for tracing.
+type gemNameKey struct{}
+
+// withGemName records the gem that requests made with ctx are for.
+func withGemName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, gemNameKey{}, name)
+}
+
+// gemNameFrom returns the gem recorded by withGemName, or "".
+func gemNameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(gemNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,106 @@
+package rubygemsclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *fakeSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *fakeSpan) RecordError(err error)              { s.err = err }
+func (s *fakeSpan) End()                               { s.ended = true }
+
+type spanKey struct{}
+
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (tr *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name, attrs: make(map[string]any)}
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// spanTransport records whether requests reach the transport with a span.
+type spanTransport struct {
+	sawSpan bool
+}
+
+func (t *spanTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	_, t.sawSpan = req.Context().Value(spanKey{}).(*fakeSpan)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTracer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"rack","version":"3.0.0"}`))
+	}))
+	defer server.Close()
+
+	tracer := &fakeTracer{}
+	transport := &spanTransport{}
+	client := NewClientWithBaseURL(server.URL,
+		WithTracer(tracer),
+		WithHTTPClient(&http.Client{Transport: transport}))
+
+	if _, err := client.GetGemInfo("rack", "3.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != "HTTP GET" || !span.ended || span.err != nil {
+		t.Errorf("unexpected span %+v", span)
+	}
+	want := map[string]any{
+		SpanAttrGemName:    "rack",
+		SpanAttrStatusCode: http.StatusOK,
+		SpanAttrEndpoint:   "/api/v1/gems/rack.json",
+	}
+	for key, value := range want {
+		if span.attrs[key] != value {
+			t.Errorf("attribute %s = %v, want %v", key, span.attrs[key], value)
+		}
+	}
+	if !transport.sawSpan {
+		t.Error("expected the span's context to reach the transport")
+	}
+}
+
+func TestWithTracer_RecordsErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	tracer := &fakeTracer{}
+	client := NewClientWithBaseURL(server.URL, WithTracer(tracer))
+	if _, err := client.DetectCapabilities(); err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	if len(tracer.spans) == 0 {
+		t.Fatal("expected a span")
+	}
+	span := tracer.spans[0]
+	if span.err == nil || !span.ended {
+		t.Errorf("expected an ended span with an error, got %+v", span)
+	}
+	if _, ok := span.attrs[SpanAttrStatusCode]; ok {
+		t.Error("expected no status code without a response")
+	}
+	if _, ok := span.attrs[SpanAttrGemName]; ok {
+		t.Error("expected no gem name for a request not about a gem")
+	}
+}
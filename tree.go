@@ -0,0 +1,93 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// DepNode is a gem in a dependency tree.
+// Nodes are shared: a gem reached along several paths (a diamond) appears as
+// the same *DepNode under each parent, so the structure is a DAG.
+type DepNode struct {
+	Info     *GemInfo
+	Children []*DepNode
+
+	// Cycle is true when this node closes a dependency cycle. It points back
+	// to an ancestor and its children are not expanded again.
+	Cycle bool
+}
+
+// BuildDependencyTree fetches a gem and its runtime dependencies recursively.
+// Each gem is fetched once per traversal, however many gems depend on it.
+// Dependencies resolve to their latest published version, matching GetGemInfo.
+func (c *Client) BuildDependencyTree(name, version string) (*DepNode, error) {
+	return c.BuildDependencyTreeContext(context.Background(), name, version)
+}
+
+// BuildDependencyTreeContext is like BuildDependencyTree but aborts when ctx is canceled.
+func (c *Client) BuildDependencyTreeContext(ctx context.Context, name, version string) (*DepNode, error) {
+	b := &treeBuilder{
+		client:  c,
+		nodes:   make(map[string]*DepNode),
+		onStack: make(map[string]bool),
+	}
+	return b.build(ctx, name, version)
+}
+
+// treeBuilder holds the visited set shared across one traversal.
+type treeBuilder struct {
+	client  *Client
+	nodes   map[string]*DepNode
+	onStack map[string]bool
+}
+
+func (b *treeBuilder) build(ctx context.Context, name, version string) (*DepNode, error) {
+	if node, ok := b.nodes[name]; ok {
+		if b.onStack[name] {
+			return &DepNode{Info: node.Info, Cycle: true}, nil
+		}
+		return node, nil
+	}
+
+	info, err := b.client.GetGemInfoContext(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency tree at %s: %w", name, err)
+	}
+
+	node := &DepNode{Info: info}
+	b.nodes[name] = node
+	b.onStack[name] = true
+	defer delete(b.onStack, name)
+
+	for _, dep := range info.Dependencies.Runtime {
+		child, err := b.build(ctx, dep.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node, nil
+}
+
+// FlattenTree returns every unique gem in the tree, in depth-first order
+// starting with the root.
+func FlattenTree(root *DepNode) []GemInfo {
+	var flat []GemInfo
+	seen := make(map[string]bool)
+
+	var walk func(*DepNode)
+	walk = func(n *DepNode) {
+		if n == nil || n.Info == nil || seen[n.Info.Name] {
+			return
+		}
+		seen[n.Info.Name] = true
+		flat = append(flat, *n.Info)
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	return flat
+}
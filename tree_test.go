@@ -0,0 +1,109 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTreeServer serves gems whose runtime dependencies are given by graph.
+func newTreeServer(t *testing.T, graph map[string][]string) (*Client, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/gems/"), ".json")
+		mu.Lock()
+		fetches[name]++
+		mu.Unlock()
+
+		deps, ok := graph[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		info := GemInfo{Name: name, Version: "1.0.0"}
+		for _, d := range deps {
+			info.Dependencies.Runtime = append(info.Dependencies.Runtime, Dependency{Name: d, Requirements: ">= 0"})
+		}
+		_ = json.NewEncoder(w).Encode(info)
+	}))
+	t.Cleanup(server.Close)
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+	return client, fetches
+}
+
+func TestBuildDependencyTree_Diamond(t *testing.T) {
+	client, fetches := newTreeServer(t, map[string][]string{
+		"app":    {"left", "right"},
+		"left":   {"shared"},
+		"right":  {"shared"},
+		"shared": nil,
+	})
+
+	root, err := client.BuildDependencyTree("app", "2.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if root.Info.Version != "2.0.0" {
+		t.Errorf("expected root version 2.0.0, got %s", root.Info.Version)
+	}
+	if fetches["shared"] != 1 {
+		t.Errorf("expected shared to be fetched once, got %d", fetches["shared"])
+	}
+	if root.Children[0].Children[0] != root.Children[1].Children[0] {
+		t.Error("expected diamond dependency to be a shared node")
+	}
+
+	flat := FlattenTree(root)
+	var names []string
+	for _, g := range flat {
+		names = append(names, g.Name)
+	}
+	if got := strings.Join(names, ","); got != "app,left,shared,right" {
+		t.Errorf("FlattenTree() = %s, want app,left,shared,right", got)
+	}
+}
+
+func TestBuildDependencyTree_Cycle(t *testing.T) {
+	client, _ := newTreeServer(t, map[string][]string{
+		"a": {"b"},
+		"b": {"a"},
+	})
+
+	root, err := client.BuildDependencyTree("a", "1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	back := root.Children[0].Children[0]
+	if !back.Cycle {
+		t.Error("expected b -> a edge to be marked as a cycle")
+	}
+	if len(back.Children) != 0 {
+		t.Error("expected cycle node not to be expanded")
+	}
+	if n := len(FlattenTree(root)); n != 2 {
+		t.Errorf("expected 2 unique gems, got %d", n)
+	}
+}
+
+func TestBuildDependencyTree_MissingDependency(t *testing.T) {
+	client, _ := newTreeServer(t, map[string][]string{
+		"app": {"ghost"},
+	})
+
+	if _, err := client.BuildDependencyTree("app", "1.0.0"); err == nil {
+		t.Fatal("expected error for missing dependency")
+	}
+}
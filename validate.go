@@ -0,0 +1,192 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldError describes one problem found by ValidateGemInfoResponse.
+type FieldError struct {
+	Field   string // JSON path, e.g. "dependencies.runtime[0].name"
+	Problem string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Problem
+}
+
+// ValidationErrors collects every field-level problem in a response.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, len(v))
+	for i, e := range v {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d validation error(s): %s", len(v), strings.Join(msgs, "; "))
+}
+
+// jsonKind names the JSON types used in the gem info schema.
+type jsonKind string
+
+const (
+	kindString jsonKind = "string"
+	kindNumber jsonKind = "number"
+	kindBool   jsonKind = "boolean"
+	kindArray  jsonKind = "array"
+	kindObject jsonKind = "object"
+)
+
+// gemInfoSchema lists the top-level fields of rubygems.org's
+// /api/v1/gems/<gem>.json response and their types. Fields not marked
+// required may be absent or null.
+var gemInfoSchema = map[string]struct {
+	kind     jsonKind
+	required bool
+}{
+	"name":              {kindString, true},
+	"version":           {kindString, true},
+	"dependencies":      {kindObject, true},
+	"downloads":         {kindNumber, false},
+	"version_downloads": {kindNumber, false},
+	"platform":          {kindString, false},
+	"authors":           {kindString, false},
+	"info":              {kindString, false},
+	"licenses":          {kindArray, false},
+	"metadata":          {kindObject, false},
+	"yanked":            {kindBool, false},
+	"sha":               {kindString, false},
+	"project_uri":       {kindString, false},
+	"gem_uri":           {kindString, false},
+	"homepage_uri":      {kindString, false},
+	"wiki_uri":          {kindString, false},
+	"documentation_uri": {kindString, false},
+	"mailing_list_uri":  {kindString, false},
+	"source_code_uri":   {kindString, false},
+	"bug_tracker_uri":   {kindString, false},
+	"changelog_uri":     {kindString, false},
+	"funding_uri":       {kindString, false},
+}
+
+// ValidateGemInfoResponse checks that body matches the shape of rubygems.org's
+// gem info response, returning ValidationErrors listing every missing or
+// mistyped field. Unknown fields are allowed. Intended for conformance-testing
+// servers that claim RubyGems compatibility.
+func ValidateGemInfoResponse(body []byte) error {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return ValidationErrors{{Field: "$", Problem: "expected object, got " + string(kindOf(doc))}}
+	}
+
+	var errs ValidationErrors
+
+	fields := make([]string, 0, len(gemInfoSchema))
+	for field := range gemInfoSchema {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		spec := gemInfoSchema[field]
+		value, present := obj[field]
+		if !present || value == nil {
+			if spec.required {
+				errs = append(errs, FieldError{Field: field, Problem: "required field missing"})
+			}
+			continue
+		}
+		if kind := kindOf(value); kind != spec.kind {
+			errs = append(errs, FieldError{Field: field, Problem: fmt.Sprintf("expected %s, got %s", spec.kind, kind)})
+		}
+	}
+
+	if licenses, ok := obj["licenses"].([]any); ok {
+		for i, l := range licenses {
+			if kindOf(l) != kindString {
+				errs = append(errs, FieldError{Field: fmt.Sprintf("licenses[%d]", i), Problem: "expected string, got " + string(kindOf(l))})
+			}
+		}
+	}
+
+	if metadata, ok := obj["metadata"].(map[string]any); ok {
+		keys := make([]string, 0, len(metadata))
+		for k := range metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if kind := kindOf(metadata[k]); kind != kindString {
+				errs = append(errs, FieldError{Field: "metadata." + k, Problem: "expected string, got " + string(kind)})
+			}
+		}
+	}
+
+	if deps, ok := obj["dependencies"].(map[string]any); ok {
+		for _, category := range []string{"development", "runtime"} {
+			errs = append(errs, validateDependencyList(deps, category)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateDependencyList checks dependencies.<category> is an array of
+// {name, requirements} string pairs.
+func validateDependencyList(deps map[string]any, category string) ValidationErrors {
+	path := "dependencies." + category
+	value, present := deps[category]
+	if !present {
+		return ValidationErrors{{Field: path, Problem: "required field missing"}}
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return ValidationErrors{{Field: path, Problem: "expected array, got " + string(kindOf(value))}}
+	}
+
+	var errs ValidationErrors
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		dep, ok := item.(map[string]any)
+		if !ok {
+			errs = append(errs, FieldError{Field: itemPath, Problem: "expected object, got " + string(kindOf(item))})
+			continue
+		}
+		for _, field := range []string{"name", "requirements"} {
+			if kind := kindOf(dep[field]); kind != kindString {
+				errs = append(errs, FieldError{Field: itemPath + "." + field, Problem: "expected string, got " + string(kind)})
+			}
+		}
+	}
+	return errs
+}
+
+// kindOf returns the JSON type of a value decoded by encoding/json.
+func kindOf(v any) jsonKind {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return kindString
+	case float64:
+		return kindNumber
+	case bool:
+		return kindBool
+	case []any:
+		return kindArray
+	case map[string]any:
+		return kindObject
+	default:
+		return jsonKind(fmt.Sprintf("%T", v))
+	}
+}
@@ -0,0 +1,88 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateGemInfoResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{
+			name: "valid rubygems.org response",
+			body: `{
+				"name": "rack", "version": "3.0.8", "downloads": 100, "authors": "Leah Neukirchen",
+				"info": "Rack provides a minimal interface", "licenses": ["MIT"],
+				"metadata": {"changelog_uri": "https://example.com"}, "sha": "abc",
+				"homepage_uri": "https://github.com/rack/rack", "wiki_uri": null,
+				"dependencies": {"development": [{"name": "minitest", "requirements": "~> 5.0"}], "runtime": []}
+			}`,
+		},
+		{
+			name:       "missing required fields",
+			body:       `{"info": "no name"}`,
+			wantFields: []string{"dependencies", "name", "version"},
+		},
+		{
+			name: "wrong types",
+			body: `{
+				"name": "rack", "version": 3, "downloads": "many", "licenses": ["MIT", 1],
+				"metadata": {"rubygems_mfa_required": true},
+				"dependencies": {"development": [], "runtime": [{"name": "json"}]}
+			}`,
+			wantFields: []string{
+				"downloads", "version", "licenses[1]", "metadata.rubygems_mfa_required",
+				"dependencies.runtime[0].requirements",
+			},
+		},
+		{
+			name:       "dependencies missing a category",
+			body:       `{"name": "rack", "version": "1.0", "dependencies": {"runtime": []}}`,
+			wantFields: []string{"dependencies.development"},
+		},
+		{
+			name:       "not an object",
+			body:       `["rack"]`,
+			wantFields: []string{"$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGemInfoResponse([]byte(tt.body))
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) {
+				t.Fatalf("expected ValidationErrors, got %v", err)
+			}
+			if len(verrs) != len(tt.wantFields) {
+				t.Fatalf("got %d errors, want %d: %v", len(verrs), len(tt.wantFields), verrs)
+			}
+			for i, field := range tt.wantFields {
+				if verrs[i].Field != field {
+					t.Errorf("error %d field = %q, want %q", i, verrs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGemInfoResponse_InvalidJSON(t *testing.T) {
+	err := ValidateGemInfoResponse([]byte(`{"name": `))
+	if err == nil {
+		t.Fatal("expected error for truncated JSON")
+	}
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		t.Error("expected a decode error, not field-level errors")
+	}
+}
@@ -0,0 +1,470 @@
+package rubygemsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CompareVersions compares two gem versions using RubyGems ordering.
+// It returns -1 if a < b, 0 if a == b, and 1 if a > b.
+// Ruby equivalent: Gem::Version#<=>
+//
+// Versions are split into numeric and alphabetic segments ("1.0.0.rc1" becomes
+// 1, 0, 0, "rc", 1). Missing segments count as 0, and an alphabetic segment
+// sorts before any number, so prereleases come before their release.
+func CompareVersions(a, b string) int {
+	as, bs := versionSegments(a), versionSegments(b)
+
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		l, r := segmentAt(as, i), segmentAt(bs, i)
+		if c := compareSegments(l, r); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// IsPrerelease reports whether a version has alphabetic segments.
+// Ruby equivalent: Gem::Version#prerelease?
+func IsPrerelease(version string) bool {
+	return strings.IndexFunc(version, unicode.IsLetter) != -1
+}
+
+// MatchesRequirement reports whether version satisfies a requirement string
+// such as "~> 3.1", ">= 1.0, < 2", or "= 1.2.3". An empty requirement matches
+// any version.
+// Ruby equivalent: Gem::Requirement#satisfied_by?
+func MatchesRequirement(version, requirement string) (bool, error) {
+	return matchRequirement(version, requirement, CompareVersions)
+}
+
+// matchesRequirement is MatchesRequirement ordered by the client's
+// comparator, so requirements filter versions the same way the client picks
+// among them.
+func (c *Client) matchesRequirement(version, requirement string) (bool, error) {
+	return matchRequirement(version, requirement, c.compareVersions)
+}
+
+// matchRequirement checks version against requirement, ordering versions
+// with compare.
+func matchRequirement(version, requirement string, compare func(a, b string) int) (bool, error) {
+	for _, part := range strings.Split(requirement, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op, target := splitConstraint(part)
+		if target == "" {
+			return false, fmt.Errorf("invalid requirement %q", part)
+		}
+
+		cmp := compare(version, target)
+		var ok bool
+		switch op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~>":
+			ok = cmp >= 0 && compare(version, pessimisticBound(target)) < 0
+		default:
+			return false, fmt.Errorf("unknown requirement operator %q", op)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// splitConstraint splits "~> 3.1" into its operator and version; no operator means "=".
+func splitConstraint(constraint string) (op, version string) {
+	i := strings.IndexFunc(constraint, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsLetter(r)
+	})
+	if i == -1 {
+		return strings.TrimSpace(constraint), ""
+	}
+	op = strings.TrimSpace(constraint[:i])
+	if op == "" {
+		op = "="
+	}
+	return op, strings.TrimSpace(constraint[i:])
+}
+
+// pessimisticBound returns the exclusive upper bound for "~> version".
+// Ruby equivalent: Gem::Version#bump
+func pessimisticBound(version string) string {
+	var segments []int
+	for _, s := range versionSegments(version) {
+		n, ok := s.(int)
+		if !ok {
+			break // prerelease segments are dropped
+		}
+		segments = append(segments, n)
+	}
+	if len(segments) > 1 {
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) == 0 {
+		return "1"
+	}
+	segments[len(segments)-1]++
+
+	parts := make([]string, len(segments))
+	for i, n := range segments {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// versionSegments splits a version into int and string segments.
+func versionSegments(version string) []any {
+	var segments []any
+	runes := []rune(version)
+	for i := 0; i < len(runes); {
+		switch {
+		case unicode.IsDigit(runes[i]):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			n, _ := strconv.Atoi(string(runes[i:j]))
+			segments = append(segments, n)
+			i = j
+		case unicode.IsLetter(runes[i]):
+			j := i
+			for j < len(runes) && unicode.IsLetter(runes[j]) {
+				j++
+			}
+			segments = append(segments, string(runes[i:j]))
+			i = j
+		default:
+			i++
+		}
+	}
+	return segments
+}
+
+func segmentAt(segments []any, i int) any {
+	if i < len(segments) {
+		return segments[i]
+	}
+	return 0
+}
+
+func compareSegments(l, r any) int {
+	ln, lIsNum := l.(int)
+	rn, rIsNum := r.(int)
+	switch {
+	case lIsNum && rIsNum:
+		return compareInts(ln, rn)
+	case lIsNum:
+		return 1
+	case rIsNum:
+		return -1
+	default:
+		return strings.Compare(l.(string), r.(string))
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareVersions orders versions using the client's configured comparator.
+func (c *Client) compareVersions(a, b string) int {
+	if c.comparator != nil {
+		return c.comparator(a, b)
+	}
+	return CompareVersions(a, b)
+}
+
+// CompareSemver compares two versions using Semantic Versioning 2.0 precedence:
+// MAJOR.MINOR.PATCH numerically, a "-prerelease" sorts before its release,
+// and "+build" metadata is ignored. Versions that are not valid semver fall
+// back to CompareVersions.
+func CompareSemver(a, b string) int {
+	av, aok := parseSemver(a)
+	bv, bok := parseSemver(b)
+	if !aok || !bok {
+		return CompareVersions(a, b)
+	}
+
+	for i := range av.core {
+		if c := compareInts(av.core[i], bv.core[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(av.pre) == 0 && len(bv.pre) == 0:
+		return 0
+	case len(av.pre) == 0:
+		return 1
+	case len(bv.pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < min(len(av.pre), len(bv.pre)); i++ {
+		if c := comparePrereleaseIdent(av.pre[i], bv.pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(av.pre), len(bv.pre))
+}
+
+type semver struct {
+	core [3]int
+	pre  []string
+}
+
+// parseSemver parses "1.2.3", "1.2.3-rc.1", or "1.2.3+build".
+func parseSemver(version string) (semver, bool) {
+	var v semver
+	version, _, _ = strings.Cut(version, "+")
+	core, pre, hasPre := strings.Cut(version, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+
+	if hasPre {
+		if pre == "" {
+			return v, false
+		}
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// comparePrereleaseIdent compares semver prerelease identifiers: numeric
+// identifiers compare numerically and sort before alphanumeric ones.
+func comparePrereleaseIdent(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// MinimalUpgrade returns the smallest published version of a gem that is at
+// least current and satisfies requirement. If current already satisfies the
+// requirement it is returned unchanged. Prereleases are only considered when
+// the requirement itself names a prerelease.
+func (c *Client) MinimalUpgrade(name, current, requirement string) (string, error) {
+	return c.MinimalUpgradeContext(context.Background(), name, current, requirement)
+}
+
+// MinimalUpgradeContext is like MinimalUpgrade but aborts when ctx is canceled.
+func (c *Client) MinimalUpgradeContext(ctx context.Context, name, current, requirement string) (string, error) {
+	ok, err := c.matchesRequirement(current, requirement)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return current, nil
+	}
+
+	versions, err := c.fetchVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	allowPrerelease := IsPrerelease(requirement)
+	best := ""
+	for _, v := range versions {
+		if IsPrerelease(v.Number) && !allowPrerelease {
+			continue
+		}
+		if c.compareVersions(v.Number, current) < 0 {
+			continue
+		}
+		if ok, _ := c.matchesRequirement(v.Number, requirement); !ok {
+			continue
+		}
+		if best == "" || c.compareVersions(v.Number, best) < 0 {
+			best = v.Number
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no version of %s at or above %s satisfies %q", name, current, requirement)
+	}
+	return best, nil
+}
+
+// GetGemInfoMatching returns info for the highest published version whose
+// leading segments equal versionPrefix, so "3.1" selects the latest 3.1.x
+// (but never 3.10). Prereleases are only considered when the prefix is one.
+// Returns ErrNoMatchingVersion when no version shares the prefix.
+func (c *Client) GetGemInfoMatching(name, versionPrefix string) (*GemInfo, error) {
+	return c.GetGemInfoMatchingContext(context.Background(), name, versionPrefix)
+}
+
+// GetGemInfoMatchingContext is like GetGemInfoMatching but aborts when ctx is canceled.
+func (c *Client) GetGemInfoMatchingContext(ctx context.Context, name, versionPrefix string) (*GemInfo, error) {
+	versions, err := c.fetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	allowPrerelease := IsPrerelease(versionPrefix)
+	best := ""
+	for _, v := range versions {
+		if IsPrerelease(v.Number) && !allowPrerelease {
+			continue
+		}
+		if !hasVersionPrefix(v.Number, versionPrefix) {
+			continue
+		}
+		if best == "" || c.compareVersions(v.Number, best) > 0 {
+			best = v.Number
+		}
+	}
+
+	if best == "" {
+		return nil, fmt.Errorf("%w: %s has no version with prefix %q", ErrNoMatchingVersion, name, versionPrefix)
+	}
+	return c.GetGemInfoContext(ctx, name, best)
+}
+
+// hasVersionPrefix reports whether version starts with the segments of prefix.
+func hasVersionPrefix(version, prefix string) bool {
+	vs, ps := versionSegments(version), versionSegments(prefix)
+	if len(ps) == 0 || len(ps) > len(vs) {
+		return false
+	}
+	for i := range ps {
+		if compareSegments(vs[i], ps[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// LatestPerMajor returns the newest version within each major version line,
+// keyed by major version (the leading numeric segment). Prereleases are
+// excluded unless IncludePrereleases is given.
+func (c *Client) LatestPerMajor(name string, opts ...ReleaseOption) (map[int]string, error) {
+	return c.LatestPerMajorContext(context.Background(), name, opts...)
+}
+
+// LatestPerMajorContext is like LatestPerMajor but aborts when ctx is canceled.
+func (c *Client) LatestPerMajorContext(ctx context.Context, name string, opts ...ReleaseOption) (map[int]string, error) {
+	var o releaseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	versions, err := c.fetchVersions(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[int]string)
+	for _, v := range versions {
+		if !o.includePrereleases && (v.Prerelease || IsPrerelease(v.Number)) {
+			continue
+		}
+		segments := versionSegments(v.Number)
+		if len(segments) == 0 {
+			continue
+		}
+		major, ok := segments[0].(int)
+		if !ok {
+			continue
+		}
+		if current, ok := latest[major]; !ok || c.compareVersions(v.Number, current) > 0 {
+			latest[major] = v.Number
+		}
+	}
+
+	return latest, nil
+}
+
+// latestVersionResponse is the body of /versions/<gem>/latest.json.
+type latestVersionResponse struct {
+	Version string `json:"version"`
+}
+
+// GetLatestVersion returns the newest stable version of a gem from
+// /versions/<gem>/latest.json, without fetching the full version list. With
+// IncludePrereleases the full list is fetched instead, since that endpoint
+// never reports prereleases.
+func (c *Client) GetLatestVersion(name string, opts ...ReleaseOption) (string, error) {
+	return c.GetLatestVersionContext(context.Background(), name, opts...)
+}
+
+// GetLatestVersionContext is like GetLatestVersion but aborts when ctx is canceled.
+func (c *Client) GetLatestVersionContext(ctx context.Context, name string, opts ...ReleaseOption) (string, error) {
+	var o releaseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.includePrereleases {
+		versions, err := c.fetchVersions(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		var latest string
+		for _, v := range versions {
+			if latest == "" || c.compareVersions(v.Number, latest) > 0 {
+				latest = v.Number
+			}
+		}
+		if latest == "" {
+			return "", fmt.Errorf("%w: %s has no versions", ErrNoMatchingVersion, name)
+		}
+		return latest, nil
+	}
+
+	var resp latestVersionResponse
+	endpoint := fmt.Sprintf("%s/versions/%s/latest.json", c.baseURL, url.PathEscape(name))
+	if err := c.getJSON(ctx, endpoint, name, "latest version", &resp); err != nil {
+		return "", err
+	}
+
+	// The endpoint answers 200 {"version":"unknown"} for gems it doesn't know
+	if resp.Version == "" || resp.Version == "unknown" {
+		return "", &APIError{StatusCode: http.StatusNotFound, GemName: name}
+	}
+	return resp.Version, nil
+}
@@ -0,0 +1,365 @@
+package rubygemsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0.0", 1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "10.0.0", -1},
+		{"1.0.0.rc1", "1.0.0", -1},
+		{"1.0.0.beta", "1.0.0.alpha", 1},
+		{"1.0.0.rc2", "1.0.0.rc10", -1},
+		{"7.1.0.beta1", "7.0.8", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesRequirement(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		want        bool
+	}{
+		{"3.1.5", "~> 3.1", true},
+		{"4.0.0", "~> 3.1", false},
+		{"3.1.5", "~> 3.1.2", true},
+		{"3.2.0", "~> 3.1.2", false},
+		{"1.5.0", ">= 1.0, < 2", true},
+		{"2.0.0", ">= 1.0, < 2", false},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.3", "!= 1.2.3", false},
+		{"0.9", "> 1.0", false},
+		{"1.0", "<= 1.0", true},
+		{"5.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" "+tt.requirement, func(t *testing.T) {
+			got, err := MatchesRequirement(tt.version, tt.requirement)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MatchesRequirement(%q, %q) = %v, want %v", tt.version, tt.requirement, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := MatchesRequirement("1.0", "=~ 1.0"); err == nil {
+		t.Error("expected error for unknown operator")
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	if !IsPrerelease("1.0.0.rc1") {
+		t.Error("expected 1.0.0.rc1 to be a prerelease")
+	}
+	if IsPrerelease("1.0.0") {
+		t.Error("expected 1.0.0 not to be a prerelease")
+	}
+}
+
+func newVersionsServer(t *testing.T, numbers ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions := make([]VersionInfo, len(numbers))
+		for i, n := range numbers {
+			versions[i] = VersionInfo{Number: n}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(versions)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// comparators lists the version orderings selection tests run against.
+var comparators = map[string]func(a, b string) int{
+	"rubygems": CompareVersions,
+	"semver":   CompareSemver,
+}
+
+func TestMinimalUpgrade(t *testing.T) {
+	for name, compare := range comparators {
+		t.Run(name, func(t *testing.T) {
+			testMinimalUpgrade(t, compare)
+		})
+	}
+}
+
+func testMinimalUpgrade(t *testing.T, compare func(a, b string) int) {
+	server := newVersionsServer(t, "3.0.0", "2.3.0.rc1", "2.2.1", "2.2.0", "2.1.0", "1.9.0")
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		comparator: compare,
+	}
+
+	tests := []struct {
+		current     string
+		requirement string
+		want        string
+		wantErr     bool
+	}{
+		{current: "2.1.0", requirement: ">= 2.2", want: "2.2.0"},
+		{current: "2.1.0", requirement: "~> 2.1", want: "2.1.0"},
+		{current: "1.9.0", requirement: ">= 2.2.1", want: "2.2.1"},
+		{current: "2.2.1", requirement: ">= 2.3", want: "3.0.0"},
+		{current: "2.2.1", requirement: ">= 2.3.0.rc1", want: "2.3.0.rc1"},
+		{current: "2.1.0", requirement: ">= 4", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+" "+tt.requirement, func(t *testing.T) {
+			got, err := client.MinimalUpgrade("test-gem", tt.current, tt.requirement)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("MinimalUpgrade(%q, %q) = %q, want %q", tt.current, tt.requirement, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinimalUpgrade_RequirementUsesComparator(t *testing.T) {
+	// A registry whose comparator orders versions newest-numbered first
+	reversed := func(a, b string) int { return CompareVersions(b, a) }
+	server := newVersionsServer(t, "3.0.0", "2.2.0", "2.1.0")
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		comparator: reversed,
+	}
+
+	// Under this ordering 3.0.0 is below 2.2, so it needs an upgrade, and
+	// 2.2.0 is the smallest version at or above both
+	got, err := client.MinimalUpgrade("test-gem", "3.0.0", ">= 2.2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "2.2.0" {
+		t.Errorf("MinimalUpgrade = %q, want 2.2.0", got)
+	}
+
+	if ok, _ := client.matchesRequirement("3.0.0", ">= 2.2"); ok {
+		t.Error("expected the client's ordering to decide the requirement")
+	}
+	if ok, _ := MatchesRequirement("3.0.0", ">= 2.2"); !ok {
+		t.Error("expected MatchesRequirement to keep RubyGems ordering")
+	}
+}
+
+func TestGetGemInfoMatching(t *testing.T) {
+	for name, compare := range comparators {
+		t.Run(name, func(t *testing.T) {
+			testGetGemInfoMatching(t, compare)
+		})
+	}
+}
+
+func testGetGemInfoMatching(t *testing.T, compare func(a, b string) int) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/versions/") {
+			versions := []VersionInfo{
+				{Number: "3.10.0"}, {Number: "3.2.0.rc1"}, {Number: "3.1.12"},
+				{Number: "3.1.9"}, {Number: "3.1.13.beta"}, {Number: "3.0.0"},
+			}
+			_ = json.NewEncoder(w).Encode(versions)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(GemInfo{Name: "test-gem"})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		comparator: compare,
+	}
+
+	info, err := client.GetGemInfoMatching("test-gem", "3.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Version != "3.1.12" {
+		t.Errorf("expected 3.1.12, got %s", info.Version)
+	}
+
+	_, err = client.GetGemInfoMatching("test-gem", "4")
+	if !errors.Is(err, ErrNoMatchingVersion) {
+		t.Errorf("expected ErrNoMatchingVersion, got %v", err)
+	}
+}
+
+func TestHasVersionPrefix(t *testing.T) {
+	tests := []struct {
+		version, prefix string
+		want            bool
+	}{
+		{"3.1.12", "3.1", true},
+		{"3.10.0", "3.1", false},
+		{"3.1", "3.1", true},
+		{"3", "3.1", false},
+		{"3.1.0", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasVersionPrefix(tt.version, tt.prefix); got != tt.want {
+			t.Errorf("hasVersionPrefix(%q, %q) = %v, want %v", tt.version, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0+build.5", "1.0.0", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		// Not semver: falls back to RubyGems ordering
+		{"1.0", "1.0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			if got := CompareSemver(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareSemver(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithVersionComparator(t *testing.T) {
+	server := newVersionsServer(t, "1.3.0", "1.2.0", "1.1.0")
+
+	var calls atomic.Int32
+	counting := func(a, b string) int {
+		calls.Add(1)
+		return CompareVersions(a, b)
+	}
+	client := NewClientWithBaseURL(server.URL, WithVersionComparator(counting))
+
+	got, err := client.MinimalUpgrade("test-gem", "1.0.0", ">= 1.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "1.1.0" {
+		t.Errorf("expected 1.1.0, got %s", got)
+	}
+	if calls.Load() == 0 {
+		t.Error("expected custom comparator to be used")
+	}
+}
+
+func TestLatestPerMajor(t *testing.T) {
+	server := newVersionsServer(t, "3.0.0.rc1", "2.10.1", "2.9.0", "2.10.0", "1.0.0", "1.12.4", "0.9.9")
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	latest, err := client.LatestPerMajor("test-gem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[int]string{0: "0.9.9", 1: "1.12.4", 2: "2.10.1"}
+	if !maps.Equal(latest, want) {
+		t.Errorf("LatestPerMajor() = %v, want %v", latest, want)
+	}
+
+	latest, err = client.LatestPerMajor("test-gem", IncludePrereleases())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest[3] != "3.0.0.rc1" {
+		t.Errorf("expected prerelease 3.0.0.rc1 for major 3, got %q", latest[3])
+	}
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	var fullListRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/versions/rails/latest.json":
+			_, _ = w.Write([]byte(`{"version":"7.1.3"}`))
+		case "/versions/missing-gem/latest.json":
+			_, _ = w.Write([]byte(`{"version":"unknown"}`))
+		case "/versions/rails.json":
+			fullListRequests.Add(1)
+			_, _ = w.Write([]byte(`[{"number":"7.2.0.beta1","prerelease":true},{"number":"7.1.3"},{"number":"7.0.8"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		baseURL:    server.URL,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+
+	got, err := client.GetLatestVersion("rails")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "7.1.3" {
+		t.Errorf("GetLatestVersion() = %q, want 7.1.3", got)
+	}
+	if n := fullListRequests.Load(); n != 0 {
+		t.Errorf("fetched the full version list %d times for a stable lookup", n)
+	}
+
+	got, err = client.GetLatestVersion("rails", IncludePrereleases())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "7.2.0.beta1" {
+		t.Errorf("GetLatestVersion(IncludePrereleases) = %q, want 7.2.0.beta1", got)
+	}
+
+	if _, err := client.GetLatestVersion("missing-gem"); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("unknown gem: got %v, want ErrGemNotFound", err)
+	}
+	if _, err := client.GetLatestVersion("other-gem"); !errors.Is(err, ErrGemNotFound) {
+		t.Errorf("404: got %v, want ErrGemNotFound", err)
+	}
+}
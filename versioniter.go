@@ -0,0 +1,99 @@
+package rubygemsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+// GetGemVersionsIter yields every version of a gem, newest first as the
+// server lists them, decoding the response as it streams in. Breaking out of
+// the loop closes the connection, so scanning for the first version that
+// matches a constraint does not download the whole history. Unlike
+// GetGemVersions there is no WithMaxVersions cap or platform filtering;
+// yanked versions are skipped as in every version query (see WithYanked).
+//
+// A failure is yielded once, with a zero VersionInfo, and ends the sequence:
+//
+//	for v, err := range client.GetGemVersionsIter("rails") {
+//		if err != nil {
+//			return err
+//		}
+//		if ok, _ := rubygems.MatchesRequirement(v.Number, "~> 6.1"); ok {
+//			return use(v)
+//		}
+//	}
+func (c *Client) GetGemVersionsIter(name string) iter.Seq2[VersionInfo, error] {
+	return c.GetGemVersionsIterContext(context.Background(), name)
+}
+
+// GetGemVersionsIterContext is like GetGemVersionsIter but aborts when ctx is canceled.
+func (c *Client) GetGemVersionsIterContext(ctx context.Context, name string) iter.Seq2[VersionInfo, error] {
+	return func(yield func(VersionInfo, error) bool) {
+		// A transformer needs the whole body, so there is nothing to stream
+		if c.transform != nil {
+			versions, err := c.fetchVersions(ctx, name)
+			if err != nil {
+				yield(VersionInfo{}, err)
+				return
+			}
+			for _, v := range versions {
+				if !yield(v, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		req, err := c.newRequest(withGemName(ctx, name), "GET", fmt.Sprintf("%s/versions/%s.json", c.baseURL, name))
+		if err != nil {
+			yield(VersionInfo{}, fmt.Errorf("failed to create request: %w", err))
+			return
+		}
+
+		resp, err := c.do(req)
+		if err != nil {
+			yield(VersionInfo{}, fmt.Errorf("failed to fetch gem versions: %w", err))
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			yield(VersionInfo{}, newAPIError(resp, name))
+			return
+		}
+
+		dec := json.NewDecoder(resp.Body)
+		if err := expectDelim(dec, '['); err != nil {
+			yield(VersionInfo{}, fmt.Errorf("failed to decode gem versions: %w", err))
+			return
+		}
+		for dec.More() {
+			var v VersionInfo
+			if err := dec.Decode(&v); err != nil {
+				yield(VersionInfo{}, fmt.Errorf("failed to decode gem versions: %w", err))
+				return
+			}
+			if v.Yanked && !c.includeYanked {
+				continue
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// expectDelim reads the next JSON token and checks that it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %q, got %v", delim, tok)
+	}
+	return nil
+}
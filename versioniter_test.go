@@ -0,0 +1,92 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetGemVersionsIter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/versions/rails.json":
+			_, _ = w.Write([]byte(`[{"number":"7.1.0"},{"number":"7.0.0"},{"number":"6.1.0"}]`))
+		case "/api/v1/versions/broken.json":
+			_, _ = w.Write([]byte(`[{"number":"1.0.0"},{"number":`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL)
+
+	var got []string
+	for v, err := range client.GetGemVersionsIter("rails") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, v.Number)
+	}
+	if want := []string{"7.1.0", "7.0.0", "6.1.0"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	var errs []error
+	for _, err := range client.GetGemVersionsIter("missing") {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrGemNotFound) {
+		t.Errorf("missing gem: got %v, want one ErrGemNotFound", errs)
+	}
+
+	got, errs = nil, nil
+	for v, err := range client.GetGemVersionsIter("broken") {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, v.Number)
+	}
+	if len(got) != 1 || len(errs) != 1 {
+		t.Errorf("truncated body: got versions %v and errors %v, want one of each", got, errs)
+	}
+}
+
+func TestGetGemVersionsIter_StopsEarly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"number":"3.0.0"},{"number":"2.0.0"},`))
+		w.(http.Flusher).Flush()
+
+		// The rest of the history never arrives unless the client waits for it
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{"number":"1.0.0"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL(server.URL, WithCompression(false))
+
+	start := time.Now()
+	var first string
+	for v, err := range client.GetGemVersionsIter("big") {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v.Number != "" {
+			first = v.Number
+			break
+		}
+	}
+	if first != "3.0.0" {
+		t.Errorf("first version = %q, want 3.0.0", first)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stopping early took %v; the iterator read the whole body", elapsed)
+	}
+}
@@ -0,0 +1,82 @@
+package rubygemsclient
+
+import (
+	"context"
+	"maps"
+	"os"
+	"time"
+)
+
+var (
+	// configWatchInterval is how often WatchConfigs checks the config files.
+	configWatchInterval = 2 * time.Second
+
+	// configReloaded, if set, is called after WatchConfigs reloads.
+	configReloaded func()
+)
+
+// fileStamp identifies a version of a file; the zero value means missing.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// WatchConfigs polls the credential files that ReloadConfigs reads and calls
+// it whenever one is created, modified or removed. It blocks until ctx is
+// canceled and then returns ctx.Err(), so run it in its own goroutine:
+//
+//	go rubygems.WatchConfigs(ctx)
+//
+// The file paths are worked out once, when WatchConfigs starts.
+func WatchConfigs(ctx context.Context) error {
+	paths := configFilePaths()
+	last := statFiles(paths)
+
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			current := statFiles(paths)
+			if !maps.Equal(current, last) {
+				last = current
+				ReloadConfigs()
+				if configReloaded != nil {
+					configReloaded()
+				}
+			}
+		}
+	}
+}
+
+// configFilePaths lists the files credentials are read from.
+func configFilePaths() []string {
+	var paths []string
+	for _, path := range []string{
+		localBundleConfigPath(),
+		globalBundleConfigPath(),
+		gemCredentialsPath(),
+		netrcPath(),
+	} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// statFiles returns the current stamp of each path.
+func statFiles(paths []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		var stamp fileStamp
+		if info, err := os.Stat(path); err == nil {
+			stamp = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		stamps[path] = stamp
+	}
+	return stamps
+}
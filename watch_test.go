@@ -0,0 +1,102 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadConfigs(t *testing.T) {
+	useBundleConfigs(t, "---\nBUNDLE_GEMS__EXAMPLE__COM: \"any:old_token\"\n", "")
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	if creds := CredentialsFor("gems.example.com"); creds == nil || creds.Token != "old_token" {
+		t.Fatalf("got %+v, want old_token", creds)
+	}
+
+	path := filepath.Join(os.Getenv("BUNDLE_APP_CONFIG"), "config")
+	if err := os.WriteFile(path, []byte("---\nBUNDLE_GEMS__EXAMPLE__COM: \"any:new_token\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still cached until reloaded
+	if creds := CredentialsFor("gems.example.com"); creds == nil || creds.Token != "old_token" {
+		t.Fatalf("got %+v before reload, want old_token", creds)
+	}
+
+	ReloadConfigs()
+	if creds := CredentialsFor("gems.example.com"); creds == nil || creds.Token != "new_token" {
+		t.Fatalf("got %+v after reload, want new_token", creds)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	ReloadConfigs()
+	if creds := CredentialsFor("gems.example.com"); creds != nil {
+		t.Errorf("got %+v after removing the config, want nil", creds)
+	}
+}
+
+func TestWatchConfigs(t *testing.T) {
+	useBundleConfigs(t, "---\nBUNDLE_GEMS__EXAMPLE__COM: \"any:old_token\"\n", "")
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+
+	interval := configWatchInterval
+	configWatchInterval = 10 * time.Millisecond
+	t.Cleanup(func() { configWatchInterval = interval })
+
+	if creds := CredentialsFor("gems.example.com"); creds == nil || creds.Token != "old_token" {
+		t.Fatalf("got %+v, want old_token", creds)
+	}
+
+	reloaded := make(chan struct{}, 1)
+	configReloaded = func() {
+		select {
+		case reloaded <- struct{}{}:
+		default:
+		}
+	}
+	t.Cleanup(func() { configReloaded = nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- WatchConfigs(ctx) }()
+
+	// Keep rewriting the file until the watcher notices: the first write may
+	// land before it takes its initial snapshot. Each write changes the size,
+	// so coarse mtime clocks do not hide it, and replaces the file whole, so
+	// the watcher never reads it half-written.
+	path := filepath.Join(os.Getenv("BUNDLE_APP_CONFIG"), "config")
+	token := "rotated_token"
+	deadline := time.After(2 * time.Second)
+	for waiting := true; waiting; {
+		token += "x"
+		if err := os.WriteFile(path+".tmp", []byte("---\nBUNDLE_GEMS__EXAMPLE__COM: \"any:"+token+"\"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-reloaded:
+			waiting = false
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("config change was not picked up")
+		}
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchConfigs returned %v, want context.Canceled", err)
+	}
+
+	if creds := CredentialsFor("gems.example.com"); creds == nil || !strings.HasPrefix(creds.Token, "rotated_token") {
+		t.Errorf("got %+v, want a rotated token", creds)
+	}
+}
@@ -0,0 +1,61 @@
+package rubygemsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrAlreadyYanked matches a *GemHostError for yanking a version that is
+// already yanked.
+var ErrAlreadyYanked = errors.New("version already yanked")
+
+// ErrNotOwner matches a *GemHostError for a 403 response, which the gem host
+// returns when the API key's account may not change the gem.
+var ErrNotOwner = errors.New("not an owner of the gem")
+
+// YankVersion removes a published version from the server's index, like gem
+// yank, via DELETE /api/v1/gems/yank. An empty or "ruby" platform means the
+// pure-Ruby build. The API key is chosen as for PushGem. A rejected yank
+// returns a *GemHostError; use errors.Is with ErrAlreadyYanked, ErrNotOwner
+// or ErrUnauthorized for the common cases.
+func (c *Client) YankVersion(name, version, platform string) error {
+	return c.YankVersionContext(context.Background(), name, version, platform)
+}
+
+// YankVersionContext is like YankVersion but aborts when ctx is canceled.
+func (c *Client) YankVersionContext(ctx context.Context, name, version, platform string) error {
+	return c.gemHostRequest(withGemName(ctx, name), "yank", http.MethodDelete, c.baseURL+"/gems/yank",
+		"application/x-www-form-urlencoded", gemHostForm(name, version, platform))
+}
+
+// UnyankVersion restores a yanked version via PUT /api/v1/gems/unyank.
+// rubygems.org no longer offers this, but some private servers do; errors
+// are reported as for YankVersion.
+func (c *Client) UnyankVersion(name, version, platform string) error {
+	return c.UnyankVersionContext(context.Background(), name, version, platform)
+}
+
+// UnyankVersionContext is like UnyankVersion but aborts when ctx is canceled.
+func (c *Client) UnyankVersionContext(ctx context.Context, name, version, platform string) error {
+	return c.gemHostRequest(withGemName(ctx, name), "unyank", http.MethodPut, c.baseURL+"/gems/unyank",
+		"application/x-www-form-urlencoded", gemHostForm(name, version, platform))
+}
+
+// gemHostForm encodes the form fields identifying one version of a gem.
+func gemHostForm(name, version, platform string) []byte {
+	form := url.Values{"gem_name": {name}, "version": {version}}
+	if !Platform(platform).IsRuby() {
+		form.Set("platform", platform)
+	}
+	return []byte(form.Encode())
+}
+
+// alreadyYanked reports whether a gem host message says the version was
+// already yanked. rubygems.org has phrased it both ways.
+func alreadyYanked(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "already been yanked") || strings.Contains(message, "already been deleted")
+}
@@ -0,0 +1,113 @@
+package rubygemsclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newGemHostServer serves yank and unyank the way rubygems.org answers them,
+// for a key that owns rack but not rails.
+func newGemHostServer(t *testing.T, requests *[]string) *httptest.Server {
+	t.Helper()
+	yanked := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// net/http only parses form bodies of POST, PUT and PATCH requests
+		body, _ := io.ReadAll(r.Body)
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, r.Method+" "+r.URL.Path+" "+form.Encode())
+		if r.Header.Get("Authorization") != "rubygems_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if form.Get("gem_name") != "rack" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("You do not have permission to delete this gem."))
+			return
+		}
+		key := form.Get("version") + "-" + form.Get("platform")
+		switch r.URL.Path {
+		case "/api/v1/gems/yank":
+			if yanked[key] {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte("The version " + form.Get("version") + " has already been yanked."))
+				return
+			}
+			yanked[key] = true
+		case "/api/v1/gems/unyank":
+			delete(yanked, key)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestYankVersion(t *testing.T) {
+	var requests []string
+	server := newGemHostServer(t, &requests)
+	client := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "rubygems_key"}))
+
+	if err := client.YankVersion("rack", "3.0.0", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.YankVersion("rack", "3.0.0", "java"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.YankVersion("rack", "3.0.0", "ruby"); !errors.Is(err, ErrAlreadyYanked) {
+		t.Errorf("expected ErrAlreadyYanked, got %v", err)
+	}
+	if err := client.UnyankVersion("rack", "3.0.0", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.YankVersion("rack", "3.0.0", ""); err != nil {
+		t.Errorf("expected yank after unyank to succeed, got %v", err)
+	}
+
+	form := url.Values{"gem_name": {"rack"}, "version": {"3.0.0"}}
+	java := url.Values{"gem_name": {"rack"}, "version": {"3.0.0"}, "platform": {"java"}}
+	want := []string{
+		"DELETE /api/v1/gems/yank " + form.Encode(),
+		"DELETE /api/v1/gems/yank " + java.Encode(),
+		"DELETE /api/v1/gems/yank " + form.Encode(),
+		"PUT /api/v1/gems/unyank " + form.Encode(),
+		"DELETE /api/v1/gems/yank " + form.Encode(),
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+}
+
+func TestYankVersion_Errors(t *testing.T) {
+	var requests []string
+	server := newGemHostServer(t, &requests)
+
+	owner := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "rubygems_key"}))
+	err := owner.YankVersion("rails", "7.0.0", "")
+	if !errors.Is(err, ErrNotOwner) {
+		t.Errorf("expected ErrNotOwner, got %v", err)
+	}
+	if errors.Is(err, ErrAlreadyYanked) || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ErrNotOwner error matched other sentinels: %v", err)
+	}
+	var hostErr *GemHostError
+	if !errors.As(err, &hostErr) || hostErr.Op != "yank" || hostErr.Message != "You do not have permission to delete this gem." {
+		t.Errorf("unexpected error %+v", hostErr)
+	}
+
+	stranger := NewClientWithBaseURL(server.URL, WithCredentials(&Credentials{Token: "other_key"}))
+	if err := stranger.UnyankVersion("rack", "3.0.0", ""); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package rubygemsclient
+
+// WithYanked controls whether version queries return yanked versions. By
+// default they are dropped, since a yanked version cannot be installed.
+// rubygems.org already leaves them out of /versions/<gem>.json; servers that
+// list them mark them with "yanked": true, which is reported in
+// VersionInfo.Yanked when they are included.
+func WithYanked(include bool) ClientOption {
+	return func(c *Client) {
+		c.includeYanked = include
+	}
+}
+
+// withoutYanked drops yanked versions unless WithYanked(true) is set. It
+// filters in place.
+func (c *Client) withoutYanked(versions []VersionInfo) []VersionInfo {
+	if c.includeYanked {
+		return versions
+	}
+	kept := versions[:0]
+	for _, v := range versions {
+		if !v.Yanked {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,58 @@
+package rubygemsclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWithYanked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"number":"2.0.1","yanked":true},
+			{"number":"2.0.0"},
+			{"number":"1.9.0","yanked":false}
+		]`))
+	}))
+	defer server.Close()
+
+	numbers := func(c *Client) []string {
+		t.Helper()
+		var got []string
+		for v, err := range c.GetGemVersionsIter("gem") {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, v.Number)
+		}
+		return got
+	}
+
+	client := NewClientWithBaseURL(server.URL)
+	versions, err := client.GetGemVersions("gem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"2.0.0", "1.9.0"}; !slices.Equal(versions, want) {
+		t.Errorf("GetGemVersions() = %v, want %v", versions, want)
+	}
+	if got := numbers(client); !slices.Equal(got, versions) {
+		t.Errorf("GetGemVersionsIter() = %v, want %v", got, versions)
+	}
+	if latest, err := client.GetLatestVersion("gem", IncludePrereleases()); err != nil || latest != "2.0.0" {
+		t.Errorf("GetLatestVersion() = %q, %v; want 2.0.0", latest, err)
+	}
+
+	withYanked := NewClientWithBaseURL(server.URL, WithYanked(true))
+	detailed, err := withYanked.GetGemVersionsDetailed("gem")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(detailed) != 3 || detailed[0].Number != "2.0.1" || !detailed[0].Yanked || detailed[1].Yanked {
+		t.Errorf("GetGemVersionsDetailed() with yanked = %+v", detailed)
+	}
+	if got := numbers(withYanked); len(got) != 3 {
+		t.Errorf("GetGemVersionsIter() with yanked = %v, want all three", got)
+	}
+}